@@ -1,16 +1,30 @@
 package api
 
 import (
-	"database/sql"
 	"errors"
 	db "github/leoflalv/bank-api/db/sqlc"
 	"github/leoflalv/bank-api/token"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 )
 
+// accountResponse adds the balances formatted with the currency exponent to
+// the account.
+type accountResponse struct {
+	db.Account
+	FormattedBalance          string `json:"formatted_balance"`
+	FormattedAvailableBalance string `json:"formatted_available_balance"`
+}
+
+func (server *Server) newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Account:                   account,
+		FormattedBalance:          server.currencies.formatAmount(account.Currency, account.Balance),
+		FormattedAvailableBalance: server.currencies.formatAmount(account.Currency, account.AvailableBalance),
+	}
+}
+
 type getAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
@@ -18,57 +32,86 @@ type getAccountRequest struct {
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	account, err := server.store.GetAccount(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if authPayload.Username != account.Owner {
-		err := errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !canReadAccount(authPayload, account) {
+		respondError(ctx, http.StatusUnauthorized, errAccountNotOwned)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
 }
 
+// accountsSort is the only ordering of the accounts listing.
+const accountsSort = "id:asc"
+
 type listAccountsRequest struct {
-	Count int32 `form:"count" binding:"required,min=1"`
-	Size  int32 `form:"size" binding:"required,min=5,max=10"`
+	pageRequest
+	Owner string `form:"owner" binding:"omitempty,alphanum"`
 }
 
 func (server *Server) listAccounts(ctx *gin.Context) {
 	var req listAccountsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	owner := req.Owner
+	if owner == "" {
+		owner = authPayload.Username
+	}
+
+	if !canReadAccountsOf(authPayload, owner) {
+		err := newClientError("not allowed to list the accounts of %s", owner)
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	after, err := decodeCursor(accountsSort, req.Cursor)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	limit := server.pageLimit(req.pageRequest)
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Offset: (req.Count - 1) * req.Size,
-		Limit:  req.Size,
+		Owner: owner,
+		Limit: limit + 1,
+	}
+	if after != nil {
+		arg.ID = after.ID
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp[i] = server.newAccountResponse(account)
+	}
+
+	ctx.JSON(http.StatusOK, newPage(rsp, limit, accountsSort, func(account accountResponse) db.PageCursor {
+		return db.PageCursor{ID: account.ID}
+	}))
 }
 
 type createAccountRequest struct {
@@ -78,7 +121,7 @@ type createAccountRequest struct {
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -91,72 +134,107 @@ func (server *Server) createAccount(ctx *gin.Context) {
 
 	account, err := server.store.CreateAccount(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
+		if errors.Is(err, db.ErrForeignKey) || errors.Is(err, db.ErrDuplicate) {
+			respondError(ctx, http.StatusForbidden, err)
+			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
 }
 
-type updateAccountRequest struct {
-	ID      int64 `json:"id" binding:"required,min=1"`
-	Balance int64 `json:"balance" binding:"required"`
+type deleteAccountRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-func (server *Server) updateAccount(ctx *gin.Context) {
-	var req updateAccountRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+func (server *Server) deleteAccount(ctx *gin.Context) {
+	var req deleteAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.UpdateAccountsParams{
-		ID:      req.ID,
-		Balance: req.Balance,
+	account, ok := server.getManagedAccount(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	if account.Balance != 0 {
+		err := newClientError("account [%d] still has a balance of %d", account.ID, account.Balance)
+		respondError(ctx, http.StatusConflict, err)
+		return
 	}
 
-	account, err := server.store.UpdateAccounts(ctx, arg)
+	hasActivity, err := server.store.AccountHasActivity(ctx, account.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if hasActivity {
+		err := newClientError("account [%d] has entries or transfers and can't be deleted", account.ID)
+		respondError(ctx, http.StatusConflict, err)
+		return
+	}
+
+	err = server.store.DeleteAccount(ctx, account.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrForeignKey) {
+			respondError(ctx, http.StatusConflict, err)
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, struct{}{})
 }
 
-type deleteAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
-}
+// getManagedAccount loads the account and checks the authenticated user is
+// allowed to change it, writing the error response when it isn't.
+func (server *Server) getManagedAccount(ctx *gin.Context, id int64) (*db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
+			return nil, false
+		}
 
-func (server *Server) deleteAccount(ctx *gin.Context) {
-	var req deleteAccountRequest
-	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		respondError(ctx, http.StatusInternalServerError, err)
+		return nil, false
 	}
 
-	err := server.store.DeleteAccount(ctx, req.ID)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canManageAccount(authPayload, account) {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
+		return nil, false
+	}
+
+	return &account, true
+}
+
+// getReadableAccount loads the account and checks the authenticated user is
+// allowed to see it, writing the error response when it isn't.
+func (server *Server) getReadableAccount(ctx *gin.Context, id int64) (*db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
+			return nil, false
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		respondError(ctx, http.StatusInternalServerError, err)
+		return nil, false
 	}
 
-	ctx.JSON(http.StatusOK, struct{}{})
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canReadAccount(authPayload, account) {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
+		return nil, false
+	}
+
+	return &account, true
 }
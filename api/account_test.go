@@ -12,20 +12,24 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
 func randomAccount(owner string) db.Account {
+	balance := util.RandomNumber(1, 10000)
 	return db.Account{
-		ID:       int64(util.RandomNumber(1, 1000)),
-		Owner:    owner,
-		Balance:  util.RandomNumber(1, 10000),
-		Currency: util.RandomCurrency(),
+		ID:               int64(util.RandomNumber(1, 1000)),
+		Owner:            owner,
+		Balance:          balance,
+		AvailableBalance: balance,
+		Currency:         randomCurrency(),
 	}
 }
 
@@ -33,20 +37,24 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
 
-	var goAccount db.Account
+	var goAccount accountResponse
 	err = json.Unmarshal(data, &goAccount)
-	require.Equal(t, account, goAccount)
+	require.NoError(t, err)
+	require.Equal(t, account, goAccount.Account)
+	// every test currency has two minor unit digits
+	require.Equal(t, util.FormatAmount(account.Balance, 2), goAccount.FormattedBalance)
+	require.Equal(t, util.FormatAmount(account.AvailableBalance, 2), goAccount.FormattedAvailableBalance)
 }
 
-func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Account) {
+func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Account, nextCursor string) {
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
 
-	var goAccounts []db.Account
-	err = json.Unmarshal(data, &goAccounts)
-	for i := range goAccounts {
-		require.Equal(t, accounts[i], goAccounts[i])
-	}
+	var page pageResponse[db.Account]
+	err = json.Unmarshal(data, &page)
+	require.NoError(t, err)
+	require.Equal(t, accounts, page.Items)
+	require.Equal(t, nextCursor, page.NextCursor)
 }
 
 func TestGetAccountAPI(t *testing.T) {
@@ -64,7 +72,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "Ok",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
@@ -78,10 +86,10 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "NotFound",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
@@ -91,7 +99,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "InternalError",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
@@ -104,7 +112,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "BadRequest",
 			accountId: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -117,7 +125,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "UnauthorizedUser",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "unauthorized_user", time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "unauthorized_user", util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
@@ -126,6 +134,20 @@ func TestGetAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name:      "BankerCanRead",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker_user", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
 		{
 			name:      "NoAuthorization",
 			accountId: account.ID,
@@ -175,39 +197,126 @@ func TestListAccountsAPI(t *testing.T) {
 		randomAccount(user.Username),
 		randomAccount(user.Username),
 		randomAccount(user.Username),
+		randomAccount(user.Username),
 	}
 
-	params := listAccountsRequest{Count: 1, Size: 5}
-	args := db.ListAccountsParams{Owner: user.Username, Limit: 5, Offset: 0}
+	pageSize := int32(5)
+	args := db.ListAccountsParams{Owner: user.Username, ID: 0, Limit: pageSize + 1}
 
 	testCases := []struct {
 		name          string
-		accountId     int64
-		count         int32
-		size          int32
+		owner         string
+		cursor        string
+		pageSize      int32
 		setupAuth     func(t *testing.T, request *http.Request, tokenManager token.Manager)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name: "Ok",
+			name:     "Ok",
+			pageSize: pageSize,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(args)).Times(1).Return(accounts[:5], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts[:5], "")
+			},
+		},
+		{
+			name:     "HasNextPage",
+			pageSize: pageSize,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(args)).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				nextCursor := encodeCursor(accountsSort, db.PageCursor{ID: accounts[4].ID})
+				requireBodyMatchAccounts(t, recorder.Body, accounts[:5], nextCursor)
+			},
+		},
+		{
+			name:     "WithCursor",
+			cursor:   encodeCursor(accountsSort, db.PageCursor{ID: 42}),
+			pageSize: pageSize,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				args := db.ListAccountsParams{Owner: user.Username, ID: 42, Limit: pageSize + 1}
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(args)).Times(1).Return(accounts[:1], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts[:1], "")
+			},
+		},
+		{
+			name: "DefaultPageSize",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				args := db.ListAccountsParams{Owner: user.Username, ID: 0, Limit: 11}
 				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(args)).Times(1).Return(accounts, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccounts(t, recorder.Body, accounts)
 			},
-			count: params.Count,
-			size:  params.Size,
 		},
 		{
-			name: "InternalError",
+			name:     "PageSizeAboveMax",
+			pageSize: 100,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				args := db.ListAccountsParams{Owner: user.Username, ID: 0, Limit: 11}
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(args)).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCursor",
+			cursor:   "not-a-cursor",
+			pageSize: pageSize,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "CursorOfAnotherListing",
+			cursor:   encodeCursor("amount:desc", db.PageCursor{ID: 42, Amount: 10}),
+			pageSize: pageSize,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			pageSize: pageSize,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(args)).Times(1).Return([]db.Account{}, sql.ErrConnDone)
@@ -215,13 +324,12 @@ func TestListAccountsAPI(t *testing.T) {
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
-			count: params.Count,
-			size:  params.Size,
 		},
 		{
-			name: "BadRequest",
+			name:     "BadRequest",
+			pageSize: -1,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
@@ -229,8 +337,35 @@ func TestListAccountsAPI(t *testing.T) {
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
-			count: 0,
-			size:  params.Size,
+		},
+		{
+			name:     "BankerListsOtherOwner",
+			owner:    user.Username,
+			pageSize: pageSize,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(args)).Times(1).Return(accounts[:5], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts[:5], "")
+			},
+		},
+		{
+			name:     "DepositorListsOtherOwner",
+			owner:    user.Username,
+			pageSize: pageSize,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "depositor", util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
 		},
 	}
 
@@ -247,9 +382,18 @@ func TestListAccountsAPI(t *testing.T) {
 			SetupRoutes(server)
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/accounts?count=%d&size=%d", tc.count, tc.size)
-
-			req, err := http.NewRequest(http.MethodGet, url, nil)
+			query := url.Values{}
+			if tc.pageSize != 0 {
+				query.Set("page_size", fmt.Sprint(tc.pageSize))
+			}
+			if tc.cursor != "" {
+				query.Set("cursor", tc.cursor)
+			}
+			if tc.owner != "" {
+				query.Set("owner", tc.owner)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/accounts?"+query.Encode(), nil)
 			require.NoError(t, err)
 
 			tc.setupAuth(t, req, server.tokenManager)
@@ -264,6 +408,8 @@ func TestListAccountsAPI(t *testing.T) {
 func TestDeleteAccountAPI(t *testing.T) {
 	user, _ := randomUser()
 	account := randomAccount(user.Username)
+	account.Balance = 0
+	fundedAccount := randomAccount(user.Username)
 
 	testCases := []struct {
 		name          string
@@ -276,9 +422,11 @@ func TestDeleteAccountAPI(t *testing.T) {
 			name:      "Ok",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountHasActivity(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(false, nil)
 				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -286,136 +434,137 @@ func TestDeleteAccountAPI(t *testing.T) {
 			},
 		},
 		{
-			name:      "NotFound",
+			name:      "BankerCannotDeleteOtherAccount",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountHasActivity(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
-			name:      "InternalError",
+			name:      "AdminDeletesOtherAccount",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountHasActivity(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(false, nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
-			name:      "BadRequest",
-			accountId: 0,
+			name:      "NotOwner",
+			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "intruder", util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountHasActivity(gomock.Any(), gomock.Any()).Times(0)
 				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			store := mockdb.NewMockStore(ctrl)
-			tc.buildStubs(store)
-
-			server := newMockServer(t, store)
-			SetupRoutes(server)
-			recorder := httptest.NewRecorder()
-
-			url := fmt.Sprintf("/account/%d", tc.accountId)
-
-			req, err := http.NewRequest(http.MethodDelete, url, nil)
-			require.NoError(t, err)
-
-			tc.setupAuth(t, req, server.tokenManager)
-			server.router.ServeHTTP(recorder, req)
-			tc.checkResponse(t, recorder)
-		})
-
-	}
-
-}
-
-func TestCreateAccountAPI(t *testing.T) {
-	user, _ := randomUser()
-	account := randomAccount(user.Username)
-	arg := db.CreateAccountParams{Owner: account.Owner, Balance: 0, Currency: account.Currency}
-
-	testCases := []struct {
-		name          string
-		accountId     int64
-		buildStubs    func(store *mockdb.MockStore)
-		setupAuth     func(t *testing.T, request *http.Request, tokenManager token.Manager)
-		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
-		body          gin.H
-	}{
 		{
-			name:      "Ok",
+			name:      "NonZeroBalance",
+			accountId: fundedAccount.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fundedAccount.ID)).Times(1).Return(fundedAccount, nil)
+				store.EXPECT().AccountHasActivity(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "HasActivity",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountHasActivity(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(true, nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
-			body: gin.H{
-				"balance":  account.Balance,
-				"currency": account.Currency,
+		},
+		{
+			name:      "ForeignKeyViolation",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountHasActivity(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(false, nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.TranslateError(&pq.Error{Code: "23503"}))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
 		},
 		{
-			name:      "InternalServerError",
+			name:      "NotFound",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
-			body: gin.H{
-				"balance":  account.Balance,
-				"currency": account.Currency,
+		},
+		{
+			name:      "InternalError",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountHasActivity(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(false, nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
 			name:      "BadRequest",
-			accountId: account.ID,
+			accountId: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
-			body: gin.H{
-				"balance": account.Balance,
-			},
 		},
 	}
 
@@ -432,38 +581,30 @@ func TestCreateAccountAPI(t *testing.T) {
 			SetupRoutes(server)
 			recorder := httptest.NewRecorder()
 
-			url := "/account"
-
-			data, err := json.Marshal(tc.body)
-			require.NoError(t, err)
+			url := fmt.Sprintf("/account/%d", tc.accountId)
 
-			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			req, err := http.NewRequest(http.MethodDelete, url, nil)
 			require.NoError(t, err)
 
 			tc.setupAuth(t, req, server.tokenManager)
 			server.router.ServeHTTP(recorder, req)
 			tc.checkResponse(t, recorder)
 		})
+
 	}
+
 }
 
-func TestUpdateAccountAPI(t *testing.T) {
+func TestCreateAccountAPI(t *testing.T) {
 	user, _ := randomUser()
 	account := randomAccount(user.Username)
-	updatedAccount := db.Account{
-		ID:        account.ID,
-		Owner:     account.Owner,
-		Currency:  account.Currency,
-		CreatedAt: account.CreatedAt,
-		Balance:   account.Balance + 1,
-	}
-	arg := db.UpdateAccountsParams{ID: account.ID, Balance: updatedAccount.Balance}
+	arg := db.CreateAccountParams{Owner: account.Owner, Balance: 0, Currency: account.Currency}
 
 	testCases := []struct {
 		name          string
 		accountId     int64
-		setupAuth     func(t *testing.T, request *http.Request, tokenManager token.Manager)
 		buildStubs    func(store *mockdb.MockStore)
+		setupAuth     func(t *testing.T, request *http.Request, tokenManager token.Manager)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 		body          gin.H
 	}{
@@ -471,69 +612,51 @@ func TestUpdateAccountAPI(t *testing.T) {
 			name:      "Ok",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().UpdateAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updatedAccount, nil)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, updatedAccount)
+				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 			body: gin.H{
-				"id":      account.ID,
-				"balance": updatedAccount.Balance,
+				"balance":  account.Balance,
+				"currency": account.Currency,
 			},
 		},
 		{
 			name:      "InternalServerError",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().UpdateAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 			body: gin.H{
-				"id":      account.ID,
-				"balance": updatedAccount.Balance,
-			},
-		},
-		{
-			name:      "NotFound",
-			accountId: account.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().UpdateAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, sql.ErrNoRows)
-			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
-			},
-			body: gin.H{
-				"id":      account.ID,
-				"balance": updatedAccount.Balance,
+				"balance":  account.Balance,
+				"currency": account.Currency,
 			},
 		},
 		{
 			name:      "BadRequest",
 			accountId: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().UpdateAccounts(gomock.Any(), gomock.Eq(arg)).Times(0)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 			body: gin.H{
-				"id":      0,
-				"balance": updatedAccount.Balance,
+				"balance": account.Balance,
 			},
 		},
 	}
@@ -556,7 +679,7 @@ func TestUpdateAccountAPI(t *testing.T) {
 			data, err := json.Marshal(tc.body)
 			require.NoError(t, err)
 
-			req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			require.NoError(t, err)
 
 			tc.setupAuth(t, req, server.tokenManager)
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adjustmentAccountRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type createAdjustmentRequest struct {
+	Amount    int64  `json:"amount" binding:"required"`
+	Reason    string `json:"reason" binding:"required"`
+	Reference string `json:"reference"`
+}
+
+func (server *Server) createAdjustment(ctx *gin.Context) {
+	var uri adjustmentAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req createAdjustmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.AdjustBalanceParams{
+		AccountID: uri.ID,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+		Reference: sql.NullString{String: req.Reference, Valid: req.Reference != ""},
+		CreatedBy: authPayload.Username,
+	}
+
+	result, err := server.store.AdjustBalance(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeInsufficientFunds, err)
+			return
+		}
+
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func requireBodyMatchAdjustment(t *testing.T, body *bytes.Buffer, adjustment db.AdjustBalanceResult) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var adjustmentResult db.AdjustBalanceResult
+	err = json.Unmarshal(data, &adjustmentResult)
+	require.Equal(t, adjustment, adjustmentResult)
+}
+
+func TestCreateAdjustmentAPI(t *testing.T) {
+	user, _ := randomUser()
+	account := randomAccount(user.Username)
+	amount := int64(-10)
+
+	arg := db.AdjustBalanceParams{
+		AccountID: account.ID,
+		Amount:    amount,
+		Reason:    "chargeback",
+		Reference: sql.NullString{String: "TICKET-42", Valid: true},
+		CreatedBy: "banker",
+	}
+
+	entry := randomEntry(account.ID, amount)
+	updatedAccount := account
+	updatedAccount.Balance += amount
+	result := db.AdjustBalanceResult{
+		Adjustment: db.Adjustment{
+			ID:        int64(util.RandomNumber(1, 1000)),
+			AccountID: account.ID,
+			EntryID:   entry.ID,
+			Amount:    amount,
+			Reason:    arg.Reason,
+			Reference: arg.Reference,
+			CreatedBy: arg.CreatedBy,
+		},
+		Account: updatedAccount,
+		Entry:   entry,
+	}
+
+	testCases := []struct {
+		name          string
+		accountId     int64
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenManager token.Manager)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			accountId: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reason":    arg.Reason,
+				"reference": arg.Reference.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAdjustment(t, recorder.Body, result)
+			},
+		},
+		{
+			name:      "WithoutReference",
+			accountId: account.ID,
+			body: gin.H{
+				"amount": amount,
+				"reason": arg.Reason,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AdjustBalanceParams{
+					AccountID: account.ID,
+					Amount:    amount,
+					Reason:    "chargeback",
+					CreatedBy: "admin",
+				}
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "DepositorNotAllowed",
+			accountId: account.ID,
+			body: gin.H{
+				"amount": amount,
+				"reason": arg.Reason,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountId: account.ID,
+			body: gin.H{
+				"amount": amount,
+				"reason": arg.Reason,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingReason",
+			accountId: account.ID,
+			body: gin.H{
+				"amount": amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "ZeroAmount",
+			accountId: account.ID,
+			body: gin.H{
+				"amount": 0,
+				"reason": arg.Reason,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountId: 0,
+			body: gin.H{
+				"amount": amount,
+				"reason": arg.Reason,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "AccountNotFound",
+			accountId: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reason":    arg.Reason,
+				"reference": arg.Reference.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AdjustBalanceResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InsufficientFunds",
+			accountId: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reason":    arg.Reason,
+				"reference": arg.Reference.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AdjustBalanceResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountId: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reason":    arg.Reason,
+				"reference": arg.Reference.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalance(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AdjustBalanceResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/adjustments", tc.accountId)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenManager)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
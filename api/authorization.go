@@ -0,0 +1,88 @@
+package api
+
+import (
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// permission is something a role is allowed to do beyond acting on the
+// user's own resources.
+type permission string
+
+const (
+	readAnyAccount   permission = "read_any_account"
+	manageAnyAccount permission = "manage_any_account"
+	adjustBalances   permission = "adjust_balances"
+	reverseTransfers permission = "reverse_transfers"
+	manageUsers      permission = "manage_users"
+)
+
+// Depositors have no extra permissions, they can only act on what they own.
+var rolePermissions = map[string][]permission{
+	util.BankerRole: {readAnyAccount, adjustBalances, reverseTransfers},
+	util.AdminRole:  {readAnyAccount, manageAnyAccount, adjustBalances, reverseTransfers, manageUsers},
+}
+
+var errAccountNotOwned = newClientError("account doesn't belong to the authenticated user")
+
+func hasPermission(payload *token.Payload, perm permission) bool {
+	for _, granted := range rolePermissions[payload.Role] {
+		if granted == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// canReadAccount reports whether the user can see the account details.
+func canReadAccount(payload *token.Payload, account db.Account) bool {
+	return payload.Username == account.Owner || hasPermission(payload, readAnyAccount)
+}
+
+// canReadAccountsOf reports whether the user can list the accounts of owner.
+func canReadAccountsOf(payload *token.Payload, owner string) bool {
+	return payload.Username == owner || hasPermission(payload, readAnyAccount)
+}
+
+// canManageAccount reports whether the user can change or close the account.
+func canManageAccount(payload *token.Payload, account db.Account) bool {
+	return payload.Username == account.Owner || hasPermission(payload, manageAnyAccount)
+}
+
+// canOperateAccount reports whether the user can move money out of the
+// account. Elevated roles can see other accounts but never spend from them.
+func canOperateAccount(payload *token.Payload, account db.Account) bool {
+	return payload.Username == account.Owner
+}
+
+// canReadScheduledTransfer reports whether the user can see the scheduled
+// transfer and its runs.
+func canReadScheduledTransfer(payload *token.Payload, scheduled db.ScheduledTransfer) bool {
+	return payload.Username == scheduled.Owner || hasPermission(payload, readAnyAccount)
+}
+
+// canManageScheduledTransfer reports whether the user can change or cancel the
+// scheduled transfer. Like transfers, only the owner can set them up.
+func canManageScheduledTransfer(payload *token.Payload, scheduled db.ScheduledTransfer) bool {
+	return payload.Username == scheduled.Owner
+}
+
+// permissionMiddleware aborts the request unless the authenticated user has
+// been granted perm. It must run after authMiddleware.
+func permissionMiddleware(perm permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if !hasPermission(authPayload, perm) {
+			err := newClientError("role %s is not allowed to %s", authPayload.Role, perm)
+			abortWithError(ctx, http.StatusForbidden, err)
+			return
+		}
+
+		ctx.Next()
+	}
+}
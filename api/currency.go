@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currencyRegistry keeps the currencies table in memory so that validating
+// requests and formatting amounts doesn't hit the database. It is loaded when
+// the server is created and refreshed in the background, so currencies added
+// or disabled in the table are picked up without a redeploy.
+type currencyRegistry struct {
+	store db.Store
+
+	mu         sync.RWMutex
+	currencies map[string]db.Currency
+	ordered    []db.Currency
+}
+
+func newCurrencyRegistry(store db.Store) *currencyRegistry {
+	return &currencyRegistry{
+		store:      store,
+		currencies: make(map[string]db.Currency),
+	}
+}
+
+// refresh replaces the registry content with the currencies table.
+func (registry *currencyRegistry) refresh(ctx context.Context) error {
+	currencies, err := registry.store.ListCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	byCode := make(map[string]db.Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.currencies = byCode
+	registry.ordered = currencies
+	return nil
+}
+
+func (registry *currencyRegistry) get(code string) (db.Currency, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	currency, ok := registry.currencies[code]
+	return currency, ok
+}
+
+// isSupported reports whether new accounts and transfers can use the currency.
+func (registry *currencyRegistry) isSupported(code string) bool {
+	currency, ok := registry.get(code)
+	return ok && currency.Enabled
+}
+
+// enabled returns the supported currencies ordered by code.
+func (registry *currencyRegistry) enabled() []db.Currency {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	currencies := []db.Currency{}
+	for _, currency := range registry.ordered {
+		if currency.Enabled {
+			currencies = append(currencies, currency)
+		}
+	}
+	return currencies
+}
+
+// exponent returns the number of digits after the separator of the currency,
+// unknown currencies have none.
+func (registry *currencyRegistry) exponent(code string) int {
+	if currency, ok := registry.get(code); ok {
+		return int(currency.Exponent)
+	}
+	return 0
+}
+
+// formatAmount writes an amount in minor units of the currency as a decimal.
+func (registry *currencyRegistry) formatAmount(code string, amount int64) string {
+	return util.FormatAmount(amount, registry.exponent(code))
+}
+
+// RefreshCurrencies reloads the currency registry every interval set in the
+// config until ctx is done.
+func (server *Server) RefreshCurrencies(ctx context.Context) {
+	if server.config.CurrencyRefreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(server.config.CurrencyRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := server.currencies.refresh(ctx); err != nil {
+				slog.ErrorContext(ctx, "cannot refresh currencies", "error", err)
+			}
+		}
+	}
+}
+
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, server.currencies.enabled())
+}
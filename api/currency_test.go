@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/util"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	registry := newCurrencyRegistry(store)
+
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+		{Code: "JPY", NumericCode: "392", Exponent: 0, Enabled: true},
+		{Code: util.USD, NumericCode: "840", Exponent: 2, Enabled: true},
+	}, nil)
+	require.NoError(t, registry.refresh(context.Background()))
+
+	require.True(t, registry.isSupported("JPY"))
+	require.False(t, registry.isSupported(util.EUR))
+	require.Equal(t, "1234", registry.formatAmount("JPY", 1234))
+	require.Equal(t, "12.34", registry.formatAmount(util.USD, 1234))
+
+	// A refresh picks up currencies added or disabled in the table
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+		{Code: util.EUR, NumericCode: "978", Exponent: 2, Enabled: true},
+		{Code: "JPY", NumericCode: "392", Exponent: 0, Enabled: false},
+		{Code: util.USD, NumericCode: "840", Exponent: 2, Enabled: true},
+	}, nil)
+	require.NoError(t, registry.refresh(context.Background()))
+
+	require.True(t, registry.isSupported(util.EUR))
+	require.False(t, registry.isSupported("JPY"))
+
+	enabled := registry.enabled()
+	require.Len(t, enabled, 2)
+	require.Equal(t, util.EUR, enabled[0].Code)
+	require.Equal(t, util.USD, enabled[1].Code)
+
+	// A failed refresh keeps the currencies already loaded
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return(nil, context.DeadlineExceeded)
+	require.Error(t, registry.refresh(context.Background()))
+	require.True(t, registry.isSupported(util.EUR))
+}
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newMockServer(t, store)
+	SetupRoutes(server)
+	recorder := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	data, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	var currencies []db.Currency
+	err = json.Unmarshal(data, &currencies)
+	require.NoError(t, err)
+	require.Equal(t, testCurrencies, currencies)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	db "github/leoflalv/bank-api/db/sqlc"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// entryResponse adds the amount formatted with the account currency exponent
+// to the entry.
+type entryResponse struct {
+	db.Entry
+	FormattedAmount string `json:"formatted_amount"`
+}
+
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req historyRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	after, err := decodeCursor(req.sort(), req.Cursor)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getReadableAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	limit := server.pageLimit(req.pageRequest)
+	entries, err := server.store.ListAccountEntriesPage(ctx, req.pageParams(account.ID, after, limit))
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := make([]entryResponse, len(entries))
+	for i, entry := range entries {
+		rsp[i] = entryResponse{
+			Entry:           entry,
+			FormattedAmount: server.currencies.formatAmount(account.Currency, entry.Amount),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, newPage(rsp, limit, req.sort(), func(entry entryResponse) db.PageCursor {
+		return db.PageCursor{ID: entry.ID, Amount: abs(entry.Amount)}
+	}))
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
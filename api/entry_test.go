@@ -0,0 +1,329 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func requireBodyMatchEntries(t *testing.T, body *bytes.Buffer, entries []db.Entry, nextCursor string) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var page pageResponse[db.Entry]
+	err = json.Unmarshal(data, &page)
+	require.NoError(t, err)
+	require.Equal(t, entries, page.Items)
+	require.Equal(t, nextCursor, page.NextCursor)
+}
+
+func TestListAccountEntriesAPI(t *testing.T) {
+	user, _ := randomUser()
+	account := randomAccount(user.Username)
+
+	entries := []db.Entry{
+		randomEntry(account.ID, 50),
+		randomEntry(account.ID, 40),
+		randomEntry(account.ID, -30),
+	}
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	query := url.Values{
+		"from":       {from.Format(time.RFC3339)},
+		"to":         {to.Format(time.RFC3339)},
+		"direction":  {"incoming"},
+		"min_amount": {"20"},
+		"max_amount": {"100"},
+		"sort_by":    {"amount"},
+		"order":      {"desc"},
+		"page_size":  {"2"},
+	}
+
+	arg := db.HistoryPageParams{
+		AccountID: account.ID,
+		FromTime:  sql.NullTime{Time: from, Valid: true},
+		ToTime:    sql.NullTime{Time: to, Valid: true},
+		Direction: sql.NullString{String: "incoming", Valid: true},
+		MinAmount: sql.NullInt64{Int64: 20, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 100, Valid: true},
+		SortBy:    db.SortByAmount,
+		SortDesc:  true,
+		Limit:     3,
+	}
+
+	withQuery := func(key, value string) url.Values {
+		values := url.Values{}
+		for k, v := range query {
+			values[k] = v
+		}
+		values.Set(key, value)
+		return values
+	}
+
+	testCases := []struct {
+		name          string
+		accountId     int64
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenManager token.Manager)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			accountId: account.ID,
+			query:     query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries[:2], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchEntries(t, recorder.Body, entries[:2], "")
+			},
+		},
+		{
+			name:      "HasNextPage",
+			accountId: account.ID,
+			query:     query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				nextCursor := encodeCursor("amount:desc", db.PageCursor{ID: entries[1].ID, Amount: 40})
+				requireBodyMatchEntries(t, recorder.Body, entries[:2], nextCursor)
+			},
+		},
+		{
+			name:      "WithCursor",
+			accountId: account.ID,
+			query:     withQuery("cursor", encodeCursor("amount:desc", db.PageCursor{ID: 7, Amount: 40})),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := arg
+				arg.After = &db.PageCursor{ID: 7, Amount: 40}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries[2:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchEntries(t, recorder.Body, entries[2:], "")
+			},
+		},
+		{
+			name:      "CursorOfAnotherSort",
+			accountId: account.ID,
+			query:     withQuery("cursor", encodeCursor("created_at:asc", db.PageCursor{ID: 7})),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "WithoutFilters",
+			accountId: account.ID,
+			query:     url.Values{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.HistoryPageParams{
+					AccountID: account.ID,
+					Limit:     11,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "BankerReadsOtherAccount",
+			accountId: account.ID,
+			query:     query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "NotOwner",
+			accountId: account.ID,
+			query:     query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "intruder", util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountId: account.ID,
+			query:     query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "AccountNotFound",
+			accountId: account.ID,
+			query:     query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidDirection",
+			accountId: account.ID,
+			query:     withQuery("direction", "sideways"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidAmountRange",
+			accountId: account.ID,
+			query:     withQuery("min_amount", "500"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidDateRange",
+			accountId: account.ID,
+			query:     withQuery("to", from.Add(-time.Hour).Format(time.RFC3339)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidDate",
+			accountId: account.ID,
+			query:     withQuery("from", "yesterday"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountId: account.ID,
+			query:     query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesPage(gomock.Any(), gomock.Any()).Times(1).Return([]db.Entry{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/entries?%s", tc.accountId, tc.query.Encode())
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenManager)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
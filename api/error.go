@@ -0,0 +1,320 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/exchange"
+	"github/leoflalv/bank-api/logging"
+	"github/leoflalv/bank-api/token"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Every error response has the same body:
+//
+//	{
+//	  "error": {
+//	    "code": "invalid_request",
+//	    "message": "request validation failed",
+//	    "details": [{"field": "amount", "message": "must be greater than 0"}],
+//	    "request_id": "5f0c1e52-..."
+//	  }
+//	}
+//
+// code is stable and meant for clients to branch on, message is for humans
+// and may change. details is only set when the request has invalid fields.
+// request_id is also sent in the X-Request-ID header and appears in the
+// server logs. Only the messages written for clients are sent, the detail of
+// other errors is only logged and the response just names the status.
+type errorEnvelope struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Details   []fieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// fieldError tells what's wrong with a field of the request, named as in the
+// JSON body, the query string or the path.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Codes used when the handler doesn't give a more specific one.
+const (
+	errCodeInvalidRequest  = "invalid_request"
+	errCodeUnauthenticated = "unauthenticated"
+	errCodeForbidden       = "forbidden"
+	errCodeNotFound        = "not_found"
+	errCodeConflict        = "conflict"
+	errCodeUnprocessable   = "unprocessable"
+	errCodeInternal        = "internal"
+)
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          errCodeInvalidRequest,
+	http.StatusUnauthorized:        errCodeUnauthenticated,
+	http.StatusForbidden:           errCodeForbidden,
+	http.StatusNotFound:            errCodeNotFound,
+	http.StatusConflict:            errCodeConflict,
+	http.StatusUnprocessableEntity: errCodeUnprocessable,
+}
+
+var errRouteNotFound = newClientError("route not found")
+
+// clientError is an error whose message is written for the client, like the
+// reason a handler refuses a request.
+type clientError struct {
+	message string
+}
+
+func (e *clientError) Error() string {
+	return e.message
+}
+
+func newClientError(format string, args ...any) error {
+	return &clientError{message: fmt.Sprintf(format, args...)}
+}
+
+// publicErrors are the errors of the other packages whose message can be
+// sent to clients as it is.
+var publicErrors = []error{
+	db.ErrAccountNotFound,
+	db.ErrRecordNotFound,
+	db.ErrDuplicate,
+	db.ErrForeignKey,
+	db.ErrInsufficientFunds,
+	db.ErrCurrencyMismatch,
+	db.ErrAmountTooSmall,
+	db.ErrIdempotencyKeyReused,
+	db.ErrReversalExceedsTransfer,
+	db.ErrReversalOfReversal,
+	db.ErrReversalOfLinkedTransfer,
+	db.ErrPaymentNotPending,
+	db.ErrHoldNotActive,
+	db.ErrHoldExpired,
+	db.ErrCaptureExceedsHold,
+	db.ErrScheduledTransferClosed,
+	db.ErrInvalidSchedule,
+	db.ErrNoRunBeforeEnd,
+	exchange.ErrRateNotFound,
+	token.ErrInvalidToken,
+	token.ErrExpiredToken,
+}
+
+// respondError writes the error envelope with the code matching status.
+func respondError(ctx *gin.Context, status int, err error) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = errCodeInternal
+	}
+	respondErrorCode(ctx, status, code, err)
+}
+
+// respondErrorCode writes the error envelope with a code specific to the
+// failure, so clients can react to it without parsing the message.
+func respondErrorCode(ctx *gin.Context, status int, code string, err error) {
+	ctx.JSON(status, newErrorEnvelope(ctx, status, code, err))
+}
+
+// abortWithError stops the handler chain and writes the error envelope.
+func abortWithError(ctx *gin.Context, status int, err error) {
+	ctx.Abort()
+	respondError(ctx, status, err)
+}
+
+// recoverWithError answers with an internal error when a handler panics.
+func recoverWithError(ctx *gin.Context, recovered any) {
+	abortWithError(ctx, http.StatusInternalServerError, fmt.Errorf("panic: %v", recovered))
+}
+
+func newErrorEnvelope(ctx *gin.Context, status int, code string, err error) errorEnvelope {
+	requestID := logging.RequestID(ctx.Request.Context())
+
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx.Request.Context(), "request failed",
+			"method", ctx.Request.Method,
+			"path", ctx.Request.URL.Path,
+			"error", err,
+		)
+		return errorEnvelope{Error: apiError{
+			Code:      code,
+			Message:   "internal server error",
+			RequestID: requestID,
+		}}
+	}
+
+	message, details, ok := publicError(err)
+	if !ok {
+		slog.InfoContext(ctx.Request.Context(), "request refused",
+			"method", ctx.Request.Method,
+			"path", ctx.Request.URL.Path,
+			"status", status,
+			"error", err,
+		)
+		message = strings.ToLower(http.StatusText(status))
+	}
+	return errorEnvelope{Error: apiError{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: requestID,
+	}}
+}
+
+// publicError returns what a client can be told about err. Binding errors
+// are turned into per-field messages and database errors into the typed
+// store errors, so no driver or library text gets through. It reports false
+// for errors with no public message.
+func publicError(err error) (string, []fieldError, bool) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		details := make([]fieldError, len(validationErrors))
+		for i, fieldErr := range validationErrors {
+			details[i] = fieldError{Field: fieldErr.Field(), Message: validationMessage(fieldErr)}
+		}
+		return "request validation failed", details, true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return "request validation failed", []fieldError{{Field: typeErr.Field, Message: typeMessage(typeErr.Type)}}, true
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return "request body is not valid JSON", nil, true
+	}
+
+	if errors.Is(err, io.EOF) {
+		return "request body is empty", nil, true
+	}
+
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return fmt.Sprintf("invalid number %q", numErr.Num), nil, true
+	}
+
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return fmt.Sprintf("invalid time %q, expected RFC 3339", timeErr.Value), nil, true
+	}
+
+	var clientErr *clientError
+	if errors.As(err, &clientErr) {
+		return clientErr.message, nil, true
+	}
+
+	for _, publicErr := range publicErrors {
+		if errors.Is(err, publicErr) {
+			return publicErr.Error(), nil, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// validationMessage describes the failed validation rule of a field.
+func validationMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	isString := fieldErr.Kind() == reflect.String
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if isString {
+			return fmt.Sprintf("must be at least %s characters long", param)
+		}
+		return "must be at least " + param
+	case "max":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters long", param)
+		}
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be less than or equal to " + param
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "nefield":
+		return "must be different from " + snakeCase(param)
+	case "email":
+		return "must be a valid email address"
+	case "alphanum":
+		return "must only contain letters and digits"
+	case "currency":
+		return "must be a supported currency"
+	case "role":
+		return "must be a valid role"
+	}
+	return "is invalid"
+}
+
+func typeMessage(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "must be an integer"
+	case reflect.Float32, reflect.Float64:
+		return "must be a number"
+	case reflect.String:
+		return "must be a string"
+	case reflect.Bool:
+		return "must be a boolean"
+	}
+	return "has the wrong type"
+}
+
+// requestFieldName names struct fields in validation errors after their
+// json, form or uri tag, the way clients send them.
+func requestFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return snakeCase(field.Name)
+}
+
+// snakeCase turns a Go field name like FromAccountID into from_account_id.
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
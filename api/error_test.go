@@ -0,0 +1,220 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func requireErrorEnvelope(t *testing.T, recorder *httptest.ResponseRecorder) apiError {
+	var envelope errorEnvelope
+	err := json.Unmarshal(recorder.Body.Bytes(), &envelope)
+	require.NoError(t, err)
+	require.NotEmpty(t, envelope.Error.RequestID)
+	require.Equal(t, recorder.Header().Get(requestIDHeader), envelope.Error.RequestID)
+	return envelope.Error
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	user, _ := randomUser()
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          string
+		requestID     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ValidationDetails",
+			method: http.MethodPost,
+			url:    "/user",
+			body:   `{"username": "not valid!", "password": "123", "email": "nope"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				apiErr := requireErrorEnvelope(t, recorder)
+				require.Equal(t, errCodeInvalidRequest, apiErr.Code)
+				require.Equal(t, []fieldError{
+					{Field: "username", Message: "must only contain letters and digits"},
+					{Field: "password", Message: "must be at least 6 characters long"},
+					{Field: "full_name", Message: "is required"},
+					{Field: "email", Message: "must be a valid email address"},
+				}, apiErr.Details)
+			},
+		},
+		{
+			name:   "WrongType",
+			method: http.MethodPost,
+			url:    "/user",
+			body:   `{"username": 42}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				apiErr := requireErrorEnvelope(t, recorder)
+				require.Equal(t, []fieldError{{Field: "username", Message: "must be a string"}}, apiErr.Details)
+			},
+		},
+		{
+			name:   "MalformedJSON",
+			method: http.MethodPost,
+			url:    "/user",
+			body:   `{"username": `,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				apiErr := requireErrorEnvelope(t, recorder)
+				require.Equal(t, "request body is not valid JSON", apiErr.Message)
+				require.Empty(t, apiErr.Details)
+			},
+		},
+		{
+			name:   "DatabaseErrorHidden",
+			method: http.MethodPost,
+			url:    "/user",
+			body:   `{"username": "someone", "password": "secret", "full_name": "Some One", "email": "someone@example.com"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.User{}, db.TranslateError(&pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "users_pkey"`}))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+
+				apiErr := requireErrorEnvelope(t, recorder)
+				require.Equal(t, errCodeForbidden, apiErr.Code)
+				require.Equal(t, db.ErrDuplicate.Error(), apiErr.Message)
+				require.NotContains(t, recorder.Body.String(), "users_pkey")
+			},
+		},
+		{
+			name:   "UnknownErrorHidden",
+			method: http.MethodPost,
+			url:    "/user/login",
+			body:   `{"username": "` + user.Username + `", "password": "wrong-password"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+				apiErr := requireErrorEnvelope(t, recorder)
+				require.Equal(t, errCodeUnauthenticated, apiErr.Code)
+				require.Equal(t, "unauthorized", apiErr.Message)
+				require.NotContains(t, recorder.Body.String(), "bcrypt")
+			},
+		},
+		{
+			name:      "InternalErrorHidden",
+			method:    http.MethodGet,
+			url:       "/user/" + user.Username,
+			requestID: "trace-123",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+				apiErr := requireErrorEnvelope(t, recorder)
+				require.Equal(t, apiError{Code: errCodeInternal, Message: "internal server error", RequestID: "trace-123"}, apiErr)
+			},
+		},
+		{
+			name:   "NotFound",
+			method: http.MethodGet,
+			url:    "/user/" + user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+
+				apiErr := requireErrorEnvelope(t, recorder)
+				require.Equal(t, errCodeNotFound, apiErr.Code)
+				require.Equal(t, db.ErrRecordNotFound.Error(), apiErr.Message)
+			},
+		},
+		{
+			name:   "UnknownRoute",
+			method: http.MethodGet,
+			url:    "/nowhere",
+			buildStubs: func(store *mockdb.MockStore) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+
+				apiErr := requireErrorEnvelope(t, recorder)
+				require.Equal(t, errCodeNotFound, apiErr.Code)
+				require.Equal(t, errRouteNotFound.Error(), apiErr.Message)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(tc.method, tc.url, bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				req.Header.Set(requestIDHeader, tc.requestID)
+			}
+
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequestIDTooLong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newMockServer(t, mockdb.NewMockStore(ctrl))
+	SetupRoutes(server)
+	recorder := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodGet, "/nowhere", nil)
+	require.NoError(t, err)
+	req.Header.Set(requestIDHeader, strings.Repeat("a", maxRequestIDLength+1))
+
+	server.router.ServeHTTP(recorder, req)
+
+	apiErr := requireErrorEnvelope(t, recorder)
+	require.Len(t, apiErr.RequestID, 36)
+}
+
+func TestSnakeCase(t *testing.T) {
+	require.Equal(t, "from_account_id", snakeCase("FromAccountID"))
+	require.Equal(t, "id", snakeCase("ID"))
+	require.Equal(t, "http_status", snakeCase("HTTPStatus"))
+	require.Equal(t, "amount", snakeCase("Amount"))
+}
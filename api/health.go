@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	checkOK     = "ok"
+	checkFailed = "failed"
+
+	defaultReadinessTimeout = 2 * time.Second
+)
+
+var errTokenManagerMissing = errors.New("token manager not initialized")
+
+type healthCheck struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+type healthResponse struct {
+	Status string        `json:"status"`
+	Checks []healthCheck `json:"checks,omitempty"`
+}
+
+// healthz tells the process is up and serving, whatever the state of its
+// dependencies.
+func (server *Server) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, healthResponse{Status: checkOK})
+}
+
+// readyz tells whether the server can take traffic: the database answers in
+// time, its schema is the one the queries expect and tokens can be checked.
+func (server *Server) readyz(ctx *gin.Context) {
+	timeout := server.config.ReadinessTimeout
+	if timeout <= 0 {
+		timeout = defaultReadinessTimeout
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	response := healthResponse{Status: checkOK}
+	for _, check := range []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{"database", server.store.Ping},
+		{"migrations", server.checkMigrations},
+		{"token_manager", server.checkTokenManager},
+	} {
+		result := runHealthCheck(checkCtx, check.name, check.run)
+		if result.Status != checkOK {
+			response.Status = checkFailed
+		}
+		response.Checks = append(response.Checks, result)
+	}
+
+	status := http.StatusOK
+	if response.Status != checkOK {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, response)
+}
+
+func runHealthCheck(ctx context.Context, name string, run func(context.Context) error) healthCheck {
+	start := time.Now()
+	err := run(ctx)
+
+	result := healthCheck{
+		Name:      name,
+		Status:    checkOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		// the probe is unauthenticated, the reason only goes to the logs
+		slog.WarnContext(ctx, "readiness check failed", "check", name, "error", err)
+		result.Status = checkFailed
+	}
+	return result
+}
+
+func (server *Server) checkMigrations(ctx context.Context) error {
+	version, dirty, err := server.store.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d failed halfway", version)
+	}
+	if version != db.SchemaVersion {
+		return fmt.Errorf("schema version is %d, expected %d", version, db.SchemaVersion)
+	}
+	return nil
+}
+
+func (server *Server) checkTokenManager(ctx context.Context) error {
+	if server.tokenManager == nil {
+		return errTokenManagerMissing
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Ping(gomock.Any()).Times(0)
+
+	server := newMockServer(t, store)
+	SetupRoutes(server)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status": "ok"}`, recorder.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(db.SchemaVersion, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireChecks(t, recorder, "ok", map[string]string{
+					"database":      "ok",
+					"migrations":    "ok",
+					"token_manager": "ok",
+				})
+			},
+		},
+		{
+			name: "DatabaseDown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(0), false, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireChecks(t, recorder, "failed", map[string]string{
+					"database":      "failed",
+					"migrations":    "failed",
+					"token_manager": "ok",
+				})
+			},
+		},
+		{
+			name: "SchemaBehind",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(db.SchemaVersion-1, false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireChecks(t, recorder, "failed", map[string]string{
+					"database":      "ok",
+					"migrations":    "failed",
+					"token_manager": "ok",
+				})
+			},
+		},
+		{
+			name: "DirtyMigration",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(db.SchemaVersion, true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireChecks(t, recorder, "failed", map[string]string{
+					"database":      "ok",
+					"migrations":    "failed",
+					"token_manager": "ok",
+				})
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireChecks(t *testing.T, recorder *httptest.ResponseRecorder, status string, checks map[string]string) {
+	var response healthResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Equal(t, status, response.Status)
+
+	got := map[string]string{}
+	for _, check := range response.Checks {
+		got[check.Name] = check.Status
+		require.GreaterOrEqual(t, check.LatencyMS, 0.0)
+	}
+	require.Equal(t, checks, got)
+
+	// the reason of a failure is only logged
+	var raw struct {
+		Checks []map[string]any `json:"checks"`
+	}
+	err = json.Unmarshal(recorder.Body.Bytes(), &raw)
+	require.NoError(t, err)
+	for _, check := range raw.Checks {
+		keys := []string{}
+		for key := range check {
+			keys = append(keys, key)
+		}
+		require.ElementsMatch(t, []string{"name", "status", "latency_ms"}, keys)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"database/sql"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"time"
+)
+
+const sortDescending = "desc"
+
+// historyRequest holds the filters shared by the entries and transfers
+// listings of an account. Zero values mean the filter isn't applied.
+type historyRequest struct {
+	pageRequest
+
+	From      time.Time `form:"from"`
+	To        time.Time `form:"to"`
+	Direction string    `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	MinAmount int64     `form:"min_amount" binding:"omitempty,min=1"`
+	MaxAmount int64     `form:"max_amount" binding:"omitempty,min=1"`
+	SortBy    string    `form:"sort_by" binding:"omitempty,oneof=created_at amount"`
+	Order     string    `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// validate checks the constraints between fields that binding tags can't
+// express.
+func (req historyRequest) validate() error {
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return newClientError("from must be before to")
+	}
+
+	if req.MinAmount != 0 && req.MaxAmount != 0 && req.MinAmount > req.MaxAmount {
+		return newClientError("min_amount can't be greater than max_amount")
+	}
+
+	return nil
+}
+
+// sort identifies the ordering of the listing, cursors are only valid for the
+// ordering they were produced with.
+func (req historyRequest) sort() string {
+	sortBy := req.SortBy
+	if sortBy == "" {
+		sortBy = db.SortByCreatedAt
+	}
+
+	if req.Order == sortDescending {
+		return sortBy + ":" + sortDescending
+	}
+	return sortBy + ":asc"
+}
+
+// pageParams translates the request into store parameters. The limit asks for
+// one extra row so newPage can tell whether there is a next page.
+func (req historyRequest) pageParams(accountID int64, after *db.PageCursor, limit int32) db.HistoryPageParams {
+	return db.HistoryPageParams{
+		AccountID: accountID,
+		FromTime:  nullTime(req.From),
+		ToTime:    nullTime(req.To),
+		Direction: nullString(req.Direction),
+		MinAmount: nullInt64(req.MinAmount),
+		MaxAmount: nullInt64(req.MaxAmount),
+		SortBy:    req.SortBy,
+		SortDesc:  req.Order == sortDescending,
+		After:     after,
+		Limit:     limit + 1,
+	}
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt64(n int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: n, Valid: n != 0}
+}
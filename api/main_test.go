@@ -1,28 +1,174 @@
 package api
 
 import (
+	"context"
+	mockdb "github/leoflalv/bank-api/db/mock"
 	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/payment"
 	"github/leoflalv/bank-api/util"
+	"net"
+	"net/http"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
 func newMockServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		MaxPageSize:          10,
 	}
 
-	server, err := NewServer(config, store)
+	// Currencies come from testCurrencies and tokens are never revoked unless
+	// a test case expects otherwise, gomock matches the expectations set
+	// before these ones first.
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().ListCurrencies(gomock.Any()).AnyTimes().Return(testCurrencies, nil)
+		mockStore.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).AnyTimes().Return(false, nil)
+	}
+
+	server, err := NewServer(config, store, payment.NewFakeRail(0))
 	require.NoError(t, err)
 
 	return server
 }
 
+// testCurrencies is the content of the currencies table seen by test servers.
+var testCurrencies = []db.Currency{
+	{Code: util.CAD, NumericCode: "124", Exponent: 2, Enabled: true},
+	{Code: util.EUR, NumericCode: "978", Exponent: 2, Enabled: true},
+	{Code: util.USD, NumericCode: "840", Exponent: 2, Enabled: true},
+}
+
+// randomCurrency picks one of testCurrencies.
+func randomCurrency() string {
+	codes := make([]string, len(testCurrencies))
+	for i, currency := range testCurrencies {
+		codes[i] = currency.Code
+	}
+
+	return util.RandomCurrency(codes)
+}
+
+// startTestServer serves a mock server on a random local port with a /slow
+// route answering once release is closed, and signals started when a
+// request reaches it.
+func startTestServer(t *testing.T) (server *Server, url string, started chan struct{}, release chan struct{}, served chan error) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListCurrencies(gomock.Any()).AnyTimes().Return(testCurrencies, nil)
+
+	config := util.Config{
+		TokenSymmetricKey: util.RandomString(32),
+	}
+	server, err := NewServer(config, store, payment.NewFakeRail(0))
+	require.NoError(t, err)
+	SetupRoutes(server)
+
+	started = make(chan struct{})
+	release = make(chan struct{})
+	server.router.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		<-release
+		ctx.Status(http.StatusOK)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served = make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	return server, "http://" + listener.Addr().String(), started, release, served
+}
+
+func TestServerTimeouts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListCurrencies(gomock.Any()).AnyTimes().Return(testCurrencies, nil)
+
+	config := util.Config{
+		TokenSymmetricKey:     util.RandomString(32),
+		HTTPReadTimeout:       time.Second,
+		HTTPReadHeaderTimeout: 2 * time.Second,
+		HTTPWriteTimeout:      3 * time.Second,
+		HTTPIdleTimeout:       4 * time.Second,
+	}
+	server, err := NewServer(config, store, payment.NewFakeRail(0))
+	require.NoError(t, err)
+
+	require.Equal(t, config.HTTPReadTimeout, server.httpServer.ReadTimeout)
+	require.Equal(t, config.HTTPReadHeaderTimeout, server.httpServer.ReadHeaderTimeout)
+	require.Equal(t, config.HTTPWriteTimeout, server.httpServer.WriteTimeout)
+	require.Equal(t, config.HTTPIdleTimeout, server.httpServer.IdleTimeout)
+}
+
+func TestServerShutdownDrainsRequests(t *testing.T) {
+	server, url, started, release, served := startTestServer(t)
+
+	response := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			response <- nil
+			return
+		}
+		resp.Body.Close()
+		response <- resp
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+
+	// The request in flight holds the shutdown back.
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown returned before the request in flight finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err := http.Get(url + "/currencies")
+	require.Error(t, err)
+
+	close(release)
+
+	resp := <-response
+	require.NotNil(t, resp)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, <-shutdown)
+	require.NoError(t, <-served)
+}
+
+func TestServerShutdownTimeout(t *testing.T) {
+	server, url, started, release, served := startTestServer(t)
+	defer close(release)
+
+	go http.Get(url + "/slow")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, <-served)
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// unmatchedRoute labels the requests matching no route, so scanners trying
+// random paths don't create a series per path.
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by route template and status.",
+	}, []string{"method", "route", "status"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to serve HTTP requests, by route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	loginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "login_failures_total",
+		Help: "Logins refused, by reason.",
+	}, []string{"reason"})
+	tokenVerificationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "token_verification_failures_total",
+		Help: "Requests refused by the authorization middleware, by reason.",
+	}, []string{"reason"})
+)
+
+// Reasons of the login and token verification failures.
+const (
+	failureUnknownUser   = "unknown_user"
+	failureWrongPassword = "wrong_password"
+	failureMissingHeader = "missing_header"
+	failureInvalidHeader = "invalid_header"
+	failureUnsupported   = "unsupported_type"
+	failureInvalidToken  = "invalid_token"
+	failureRevokedToken  = "revoked_token"
+)
+
+// metricsMiddleware counts the requests and their duration by the template
+// of the route they matched, like /accounts/:id, not by path.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := ctx.Request.Method
+
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(ctx.Writer.Status())).Inc()
+		httpRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+
+	server := newMockServer(t, store)
+	SetupRoutes(server)
+
+	for _, req := range []struct{ method, url, body string }{
+		{http.MethodPost, "/user/login", `{"username": "nobody", "password": "secret"}`},
+		{http.MethodGet, "/account/1", ""},
+		{http.MethodGet, "/nowhere/42", ""},
+	} {
+		request := httptest.NewRequest(req.method, req.url, strings.NewReader(req.body))
+		server.router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	require.Contains(t, body, `http_requests_total{method="POST",route="/user/login",status="404"}`)
+	require.Contains(t, body, `http_requests_total{method="GET",route="/account/:id",status="401"}`)
+	require.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"}`)
+	require.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/account/:id"}`)
+	require.Contains(t, body, `login_failures_total{reason="unknown_user"}`)
+	require.Contains(t, body, `token_verification_failures_total{reason="missing_header"}`)
+	require.NotContains(t, body, "/nowhere/42")
+}
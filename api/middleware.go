@@ -1,13 +1,15 @@
 package api
 
 import (
-	"errors"
-	"fmt"
+	"github/leoflalv/bank-api/logging"
 	"github/leoflalv/bank-api/token"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
@@ -16,34 +18,57 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenManager token.Manager) gin.HandlerFunc {
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+var errRevokedToken = newClientError("token has been revoked")
+
+func authMiddleware(tokenManager token.Manager, revocations *tokenRevocationCache) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			tokenVerificationFailures.WithLabelValues(failureMissingHeader).Inc()
+			err := newClientError("authorization header is not provided")
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			tokenVerificationFailures.WithLabelValues(failureInvalidHeader).Inc()
+			err := newClientError("invalid authorization header format")
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			tokenVerificationFailures.WithLabelValues(failureUnsupported).Inc()
+			err := newClientError("unsupported authorization type %s", authorizationType)
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		accessToken := fields[1]
-		payload, err := tokenManager.VerifyToken(accessToken)
+		payload, err := tokenManager.VerifyToken(accessToken, token.AccessToken)
+		if err != nil {
+			tokenVerificationFailures.WithLabelValues(failureInvalidToken).Inc()
+			abortWithError(ctx, http.StatusUnauthorized, err)
+			return
+		}
+
+		revoked, err := revocations.isRevoked(ctx, payload)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		if revoked {
+			tokenVerificationFailures.WithLabelValues(failureRevokedToken).Inc()
+			abortWithError(ctx, http.StatusUnauthorized, errRevokedToken)
 			return
 		}
 
@@ -51,3 +76,39 @@ func authMiddleware(tokenManager token.Manager) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// requestIDMiddleware identifies every request, keeping the id sent by the
+// client or a proxy when there is one. The id is echoed in the response
+// headers and in error responses, and carried by the request context so
+// everything logged while serving the request, store calls included, is
+// tagged with it.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.New().String()
+		}
+
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Header(requestIDHeader, requestID)
+		ctx.Next()
+	}
+}
+
+// loggerMiddleware logs every request once served.
+func loggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		slog.InfoContext(ctx.Request.Context(), "request served",
+			"method", ctx.Request.Method,
+			"path", ctx.Request.URL.Path,
+			"route", ctx.FullPath(),
+			"status", ctx.Writer.Status(),
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", ctx.ClientIP(),
+			"bytes", ctx.Writer.Size(),
+		)
+	}
+}
@@ -1,14 +1,21 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/logging"
 	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,10 +25,12 @@ func addAuthorization(
 	tokenManager token.Manager,
 	authorizationType string,
 	username string,
+	role string,
 	duration time.Duration,
 ) {
-	token, err := tokenManager.CreateToken(username, duration)
+	token, payload, err := tokenManager.CreateToken(username, role, token.AccessToken, duration)
 	require.NoError(t, err)
+	require.NotEmpty(t, payload)
 
 	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
 	request.Header.Set(authorizationHeaderKey, authorizationHeader)
@@ -31,20 +40,23 @@ func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, tokenManager token.Manager)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "Ok",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "user", time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "user", util.DepositorRole, time.Minute)
 			},
+			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
-			name:      "NoAuthorization",
-			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {},
+			name:       "NoAuthorization",
+			setupAuth:  func(t *testing.T, request *http.Request, tokenManager token.Manager) {},
+			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -52,8 +64,9 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "UnsupportedAuthorization",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, "unsupported", "user", time.Minute)
+				addAuthorization(t, request, tokenManager, "unsupported", "user", util.DepositorRole, time.Minute)
 			},
+			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -61,8 +74,9 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "InvalidAuthorizationFormat",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, "", "user", time.Minute)
+				addAuthorization(t, request, tokenManager, "", "user", util.DepositorRole, time.Minute)
 			},
+			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -70,25 +84,70 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "Ok",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "user", -time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "user", util.DepositorRole, -time.Minute)
 			},
+			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				refreshToken, _, err := tokenManager.CreateToken("user", util.DepositorRole, token.RefreshToken, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevokedToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "user", util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(1).Return(true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevocationLookupError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "user", util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(1).Return(false, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			server := newMockServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
 			SetupRoutes(server)
 
 			authPath := "/auth"
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenManager),
+				authMiddleware(server.tokenManager, server.revocations),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -104,3 +163,31 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestIDReachesStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, username string) (db.User, error) {
+			require.Equal(t, "trace-456", logging.RequestID(ctx))
+			return user, nil
+		})
+
+	server := newMockServer(t, store)
+	SetupRoutes(server)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/user/"+user.Username, nil)
+	require.NoError(t, err)
+	request.Header.Set(requestIDHeader, "trace-456")
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "trace-456", recorder.Header().Get(requestIDHeader))
+}
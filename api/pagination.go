@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	db "github/leoflalv/bank-api/db/sqlc"
+)
+
+// defaultMaxPageSize is used when the configuration doesn't set one.
+const defaultMaxPageSize = 50
+
+var errInvalidCursor = newClientError("invalid cursor")
+
+// pageRequest holds the pagination parameters shared by the list endpoints.
+// An empty cursor asks for the first page.
+type pageRequest struct {
+	Cursor   string `form:"cursor"`
+	PageSize int32  `form:"page_size" binding:"omitempty,min=1"`
+}
+
+// pageResponse is the body of every list endpoint. NextCursor is empty on the
+// last page.
+type pageResponse[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageToken is what an opaque cursor carries. The sort is kept along the
+// position so a cursor can't be replayed against a different ordering.
+type pageToken struct {
+	Sort string `json:"sort"`
+	db.PageCursor
+}
+
+func encodeCursor(sort string, cursor db.PageCursor) string {
+	data, _ := json.Marshal(pageToken{Sort: sort, PageCursor: cursor})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(sort string, cursor string) (*db.PageCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var token pageToken
+	if err := json.Unmarshal(data, &token); err != nil || token.Sort != sort {
+		return nil, errInvalidCursor
+	}
+
+	return &token.PageCursor, nil
+}
+
+// pageLimit caps the requested page size to the configured maximum, which is
+// also the default.
+func (server *Server) pageLimit(req pageRequest) int32 {
+	maxPageSize := server.config.MaxPageSize
+	if maxPageSize <= 0 {
+		maxPageSize = defaultMaxPageSize
+	}
+
+	if req.PageSize == 0 || req.PageSize > maxPageSize {
+		return maxPageSize
+	}
+	return req.PageSize
+}
+
+// newPage builds the response from rows fetched with one extra row beyond
+// limit, whose presence tells there is a next page.
+func newPage[T any](items []T, limit int32, sort string, cursorOf func(T) db.PageCursor) pageResponse[T] {
+	if int32(len(items)) <= limit {
+		return pageResponse[T]{Items: items}
+	}
+
+	items = items[:limit]
+	return pageResponse[T]{
+		Items:      items,
+		NextCursor: encodeCursor(sort, cursorOf(items[len(items)-1])),
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/payment"
+	"github/leoflalv/bank-api/token"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type paymentAccountRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type createPaymentRequest struct {
+	Amount   int64  `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+func (server *Server) createDeposit(ctx *gin.Context) {
+	server.createPayment(ctx, db.PaymentDeposit)
+}
+
+func (server *Server) createWithdrawal(ctx *gin.Context) {
+	server.createPayment(ctx, db.PaymentWithdrawal)
+}
+
+// createPayment records the payment, hands it to the payment rail and settles
+// it with the outcome. Payments the rail hasn't settled yet are answered with
+// 202 and stay pending, worker.PaymentSettler settles them later.
+func (server *Server) createPayment(ctx *gin.Context, kind string) {
+	var uri paymentAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req createPaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	account, valid := server.getValidAccount(ctx, uri.ID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canOperateAccount(authPayload, *account) {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
+		return
+	}
+
+	started, err := server.store.StartPayment(ctx, db.StartPaymentParams{
+		Kind:      kind,
+		AccountID: account.ID,
+		Amount:    req.Amount,
+		CreatedBy: authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeInsufficientFunds, err)
+			return
+		}
+
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	railReq := payment.Request{
+		PaymentID: started.ID,
+		AccountID: started.AccountID,
+		Amount:    started.Amount,
+		Currency:  started.Currency,
+	}
+
+	var result payment.Result
+	if kind == db.PaymentDeposit {
+		result, err = server.rail.Collect(ctx, railReq)
+	} else {
+		result, err = server.rail.Payout(ctx, railReq)
+	}
+	if err != nil {
+		// The rail may still have taken the payment, it stays pending until
+		// the settler gets its outcome from the rail or it times out.
+		slog.ErrorContext(ctx, "payment rail error", "payment_id", started.ID, "error", err)
+		ctx.JSON(http.StatusAccepted, started)
+		return
+	}
+
+	settled, err := server.store.SettlePayment(ctx, db.SettlePaymentParams{
+		ID:            started.ID,
+		Status:        result.Status,
+		RailReference: sql.NullString{String: result.Reference, Valid: result.Reference != ""},
+		FailureReason: sql.NullString{String: result.FailureReason, Valid: result.FailureReason != ""},
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if settled.Status == db.PaymentPending {
+		ctx.JSON(http.StatusAccepted, settled)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settled)
+}
+
+type getPaymentRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getPayment(ctx *gin.Context) {
+	var req getPaymentRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := server.store.GetPayment(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, result.AccountID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canReadAccount(authPayload, account) {
+		err := newClientError("payment %d doesn't belong to the authenticated user", result.ID)
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
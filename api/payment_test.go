@@ -0,0 +1,354 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/payment"
+	"github/leoflalv/bank-api/util"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// unavailableRail fails every call as if the rail couldn't be reached.
+type unavailableRail struct{}
+
+func (unavailableRail) Collect(ctx context.Context, req payment.Request) (payment.Result, error) {
+	return payment.Result{}, errors.New("rail unavailable")
+}
+
+func (unavailableRail) Payout(ctx context.Context, req payment.Request) (payment.Result, error) {
+	return payment.Result{}, errors.New("rail unavailable")
+}
+
+func (unavailableRail) Status(ctx context.Context, req payment.Request) (payment.Result, error) {
+	return payment.Result{}, errors.New("rail unavailable")
+}
+
+func randomPayment(account db.Account, kind string, amount int64) db.Payment {
+	return db.Payment{
+		ID:        util.RandomNumber(1, 1000),
+		AccountID: account.ID,
+		Kind:      kind,
+		Amount:    amount,
+		Currency:  account.Currency,
+		Status:    db.PaymentPending,
+		CreatedBy: account.Owner,
+	}
+}
+
+// otherCurrency returns a supported currency different from currency.
+func otherCurrency(currency string) string {
+	if currency == util.USD {
+		return util.EUR
+	}
+	return util.USD
+}
+
+func requireBodyMatchPayment(t *testing.T, body *bytes.Buffer, payment db.Payment) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotPayment db.Payment
+	err = json.Unmarshal(data, &gotPayment)
+	require.NoError(t, err)
+	require.Equal(t, payment, gotPayment)
+}
+
+func TestCreatePaymentAPI(t *testing.T) {
+	user, _ := randomUser()
+	account := randomAccount(user.Username)
+	amount := int64(100)
+
+	deposit := randomPayment(account, db.PaymentDeposit, amount)
+	completedDeposit := deposit
+	completedDeposit.Status = db.PaymentCompleted
+	completedDeposit.RailReference = sql.NullString{String: fmt.Sprintf("fake-%d", deposit.ID), Valid: true}
+	completedDeposit.TransferID = sql.NullInt64{Int64: util.RandomNumber(1, 1000), Valid: true}
+
+	withdrawal := randomPayment(account, db.PaymentWithdrawal, amount)
+	withdrawal.TransferID = sql.NullInt64{Int64: util.RandomNumber(1, 1000), Valid: true}
+	failedWithdrawal := withdrawal
+	failedWithdrawal.Status = db.PaymentFailed
+	failedWithdrawal.RailReference = sql.NullString{String: fmt.Sprintf("fake-%d", withdrawal.ID), Valid: true}
+	failedWithdrawal.FailureReason = sql.NullString{String: "amount above the rail limit of 50", Valid: true}
+	failedWithdrawal.RefundTransferID = sql.NullInt64{Int64: util.RandomNumber(1, 1000), Valid: true}
+
+	testCases := []struct {
+		name          string
+		path          string
+		username      string
+		currency      string
+		rail          payment.PaymentRail
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Deposit",
+			path:     "deposits",
+			username: user.Username,
+			currency: account.Currency,
+			rail:     payment.NewFakeRail(0),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StartPayment(gomock.Any(), gomock.Eq(db.StartPaymentParams{
+					Kind:      db.PaymentDeposit,
+					AccountID: account.ID,
+					Amount:    amount,
+					CreatedBy: user.Username,
+				})).Times(1).Return(deposit, nil)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Eq(db.SettlePaymentParams{
+					ID:            deposit.ID,
+					Status:        db.PaymentCompleted,
+					RailReference: completedDeposit.RailReference,
+				})).Times(1).Return(completedDeposit, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPayment(t, recorder.Body, completedDeposit)
+			},
+		},
+		{
+			name:     "WithdrawalFailedOnRail",
+			path:     "withdrawals",
+			username: user.Username,
+			currency: account.Currency,
+			rail:     payment.NewFakeRail(50),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StartPayment(gomock.Any(), gomock.Eq(db.StartPaymentParams{
+					Kind:      db.PaymentWithdrawal,
+					AccountID: account.ID,
+					Amount:    amount,
+					CreatedBy: user.Username,
+				})).Times(1).Return(withdrawal, nil)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Eq(db.SettlePaymentParams{
+					ID:            withdrawal.ID,
+					Status:        db.PaymentFailed,
+					RailReference: failedWithdrawal.RailReference,
+					FailureReason: failedWithdrawal.FailureReason,
+				})).Times(1).Return(failedWithdrawal, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPayment(t, recorder.Body, failedWithdrawal)
+			},
+		},
+		{
+			name:     "RailUnavailable",
+			path:     "deposits",
+			username: user.Username,
+			currency: account.Currency,
+			rail:     unavailableRail{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StartPayment(gomock.Any(), gomock.Any()).Times(1).Return(deposit, nil)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				requireBodyMatchPayment(t, recorder.Body, deposit)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			path:     "withdrawals",
+			username: user.Username,
+			currency: account.Currency,
+			rail:     payment.NewFakeRail(0),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StartPayment(gomock.Any(), gomock.Any()).Times(1).Return(db.Payment{}, db.ErrInsufficientFunds)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+		},
+		{
+			name:     "CurrencyMismatch",
+			path:     "deposits",
+			username: user.Username,
+			currency: otherCurrency(account.Currency),
+			rail:     payment.NewFakeRail(0),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StartPayment(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			path:     "withdrawals",
+			username: "other",
+			currency: account.Currency,
+			rail:     payment.NewFakeRail(0),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StartPayment(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "AccountNotFound",
+			path:     "deposits",
+			username: user.Username,
+			currency: account.Currency,
+			rail:     payment.NewFakeRail(0),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().StartPayment(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "SettleError",
+			path:     "deposits",
+			username: user.Username,
+			currency: account.Currency,
+			rail:     payment.NewFakeRail(0),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StartPayment(gomock.Any(), gomock.Any()).Times(1).Return(deposit, nil)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Any()).Times(1).Return(db.Payment{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			server.rail = tc.rail
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"amount":   amount,
+				"currency": tc.currency,
+			})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, tc.path)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenManager, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetPaymentAPI(t *testing.T) {
+	user, _ := randomUser()
+	account := randomAccount(user.Username)
+	deposit := randomPayment(account, db.PaymentDeposit, 100)
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Ok",
+			username: user.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPayment(gomock.Any(), gomock.Eq(deposit.ID)).Times(1).Return(deposit, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPayment(t, recorder.Body, deposit)
+			},
+		},
+		{
+			name:     "Banker",
+			username: "banker",
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPayment(gomock.Any(), gomock.Eq(deposit.ID)).Times(1).Return(deposit, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Forbidden",
+			username: "other",
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPayment(gomock.Any(), gomock.Eq(deposit.ID)).Times(1).Return(deposit, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPayment(gomock.Any(), gomock.Eq(deposit.ID)).Times(1).Return(db.Payment{}, db.ErrRecordNotFound)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/payments/%d", deposit.ID)
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenManager, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errReversalNotAllowed        = newClientError("only the sender of the transfer can reverse it")
+	errReversalConsentNotAllowed = newClientError("only the recipient of the transfer can consent to reverse it")
+	errReversalConsentMissing    = newClientError("the recipient hasn't consented to reverse the transfer")
+)
+
+// An empty body or a zero amount reverses everything left of the transfer.
+// The amount is in the currency the transfer was sent in.
+type reverseTransferRequest struct {
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// reverseTransfer gives money back to the sender of a transfer. Elevated roles
+// can reverse any transfer, the sender only once the recipient consented.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri getTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, ok := server.loadTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !hasPermission(authPayload, reverseTransfers) {
+		fromAccount, err := server.store.GetAccount(ctx, transfer.FromAccountID)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !canOperateAccount(authPayload, fromAccount) {
+			respondError(ctx, http.StatusForbidden, errReversalNotAllowed)
+			return
+		}
+
+		_, err = server.store.GetReversalConsent(ctx, transfer.ID)
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				respondErrorCode(ctx, http.StatusForbidden, errCodeReversalConsentRequired, errReversalConsentMissing)
+				return
+			}
+
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	result, err := server.store.ReverseTransfer(ctx, db.ReverseTransferParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrReversalExceedsTransfer):
+			respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeReversalExceedsTransfer, err)
+		case errors.Is(err, db.ErrReversalOfReversal):
+			respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeReversalOfReversal, err)
+		case errors.Is(err, db.ErrReversalOfLinkedTransfer):
+			respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeReversalOfLinkedTransfer, err)
+		default:
+			transactionErrorResponse(ctx, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// consentToReversal lets the recipient of a transfer agree to give it back.
+func (server *Server) consentToReversal(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, ok := server.loadTransfer(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canOperateAccount(authPayload, toAccount) {
+		respondError(ctx, http.StatusForbidden, errReversalConsentNotAllowed)
+		return
+	}
+
+	consent, err := server.store.CreateReversalConsent(ctx, db.CreateReversalConsentParams{
+		TransferID: transfer.ID,
+		GrantedBy:  authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicate) {
+			respondError(ctx, http.StatusConflict, err)
+			return
+		}
+
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, consent)
+}
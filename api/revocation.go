@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+
+	"github.com/google/uuid"
+)
+
+// tokenRevocationCache sits in front of the revoked tokens lookup so that
+// authMiddleware doesn't hit the database on every request. Revocations made
+// through this server are applied immediately, the ones made by other
+// instances are picked up once the cached answer expires.
+type tokenRevocationCache struct {
+	store db.Store
+	ttl   time.Duration
+
+	mu         sync.Mutex
+	tokens     map[uuid.UUID]revocationEntry
+	validAfter map[string]time.Time
+	lastSweep  time.Time
+}
+
+type revocationEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+func newTokenRevocationCache(store db.Store, ttl time.Duration) *tokenRevocationCache {
+	return &tokenRevocationCache{
+		store:      store,
+		ttl:        ttl,
+		tokens:     make(map[uuid.UUID]revocationEntry),
+		validAfter: make(map[string]time.Time),
+		lastSweep:  time.Now(),
+	}
+}
+
+// isRevoked reports whether the token described by payload was revoked.
+func (cache *tokenRevocationCache) isRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	now := time.Now()
+
+	cache.mu.Lock()
+	if validAfter, ok := cache.validAfter[payload.Username]; ok && payload.IssuedAt.Before(validAfter) {
+		cache.mu.Unlock()
+		return true, nil
+	}
+	entry, ok := cache.tokens[payload.Id]
+	cache.mu.Unlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := cache.store.IsTokenRevoked(ctx, db.IsTokenRevokedParams{
+		ID:       payload.Id,
+		Username: payload.Username,
+		IssuedAt: payload.IssuedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// A revocation never goes back, so it can be kept until the token expires
+	expiresAt := now.Add(cache.ttl)
+	if revoked {
+		expiresAt = payload.ExpiredAt
+	}
+	cache.set(payload.Id, revocationEntry{revoked: revoked, expiresAt: expiresAt})
+
+	return revoked, nil
+}
+
+// revoke records a single revoked token.
+func (cache *tokenRevocationCache) revoke(payload *token.Payload) {
+	cache.set(payload.Id, revocationEntry{revoked: true, expiresAt: payload.ExpiredAt})
+}
+
+// revokeAll records that every token issued to username before validAfter is
+// revoked.
+func (cache *tokenRevocationCache) revokeAll(username string, validAfter time.Time) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.validAfter[username] = validAfter
+}
+
+func (cache *tokenRevocationCache) set(id uuid.UUID, entry revocationEntry) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.tokens[id] = entry
+
+	now := time.Now()
+	if now.Sub(cache.lastSweep) < cache.ttl {
+		return
+	}
+
+	for id, entry := range cache.tokens {
+		if now.After(entry.expiresAt) {
+			delete(cache.tokens, id)
+		}
+	}
+	cache.lastSweep = now
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRevocationCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	cache := newTokenRevocationCache(store, time.Minute)
+
+	payload1, err := token.NewPayload(util.RandomString(6), util.DepositorRole, token.AccessToken, time.Minute)
+	require.NoError(t, err)
+
+	// Only the first lookup reaches the store
+	store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+
+	for i := 0; i < 3; i++ {
+		revoked, err := cache.isRevoked(context.Background(), payload1)
+		require.NoError(t, err)
+		require.False(t, revoked)
+	}
+
+	cache.revoke(payload1)
+	revoked, err := cache.isRevoked(context.Background(), payload1)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	payload2, err := token.NewPayload(payload1.Username, util.DepositorRole, token.AccessToken, time.Minute)
+	require.NoError(t, err)
+
+	cache.revokeAll(payload2.Username, time.Now())
+	revoked, err = cache.isRevoked(context.Background(), payload2)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
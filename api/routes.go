@@ -1,34 +1,84 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func SetupRoutes(server *Server) {
-	router := gin.Default()
+	router := gin.New()
+	// Handlers pass the gin context to the store, it has to carry the values
+	// of the request context, like the request id.
+	router.ContextWithFallback = true
+	router.Use(requestIDMiddleware(), tracingMiddleware(), metricsMiddleware(), loggerMiddleware(), gin.CustomRecovery(recoverWithError))
+	router.NoRoute(func(ctx *gin.Context) {
+		respondError(ctx, http.StatusNotFound, errRouteNotFound)
+	})
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+		v.RegisterTagNameFunc(requestFieldName)
+		v.RegisterValidation("currency", validCurrency(server.currencies))
+		v.RegisterValidation("role", validRole)
 	}
 
+	// Health
+	router.GET("/healthz", server.healthz)
+	router.GET("/readyz", server.readyz)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	// Users
 	router.GET("/user/:username", server.getUser)
 	router.POST("/user", server.createUser)
 	router.POST("/user/login", server.loginUser)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenManager))
+	// Tokens
+	router.POST("/tokens/renew_access", server.renewAccessToken)
+
+	// Currencies
+	router.GET("/currencies", server.listCurrencies)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenManager, server.revocations))
+
+	// Sessions
+	authRoutes.POST("/user/logout", server.logoutUser)
+	authRoutes.POST("/user/logout-all", server.logoutAll)
+
+	// Users administration
+	authRoutes.PUT("/user/:username/role", permissionMiddleware(manageUsers), server.updateUserRole)
 
 	// Accounts
 	authRoutes.GET("/account/:id", server.getAccount)
 	authRoutes.GET("/accounts", server.listAccounts)
 	authRoutes.POST("/account", server.createAccount)
-	authRoutes.PUT("/account", server.updateAccount)
 	authRoutes.DELETE("/account/:id", server.deleteAccount)
+	authRoutes.POST("/accounts/:id/adjustments", permissionMiddleware(adjustBalances), server.createAdjustment)
+	authRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
+	authRoutes.GET("/accounts/:id/transfers", server.listAccountTransfers)
+	authRoutes.GET("/accounts/:id/statement", server.getAccountStatement)
+	authRoutes.POST("/accounts/:id/deposits", server.createDeposit)
+	authRoutes.POST("/accounts/:id/withdrawals", server.createWithdrawal)
 
 	// Transfers
 	authRoutes.POST("/transaction", server.createTransaction)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	authRoutes.POST("/transfers/:id/reversal-consent", server.consentToReversal)
+
+	// Payments
+	authRoutes.GET("/payments/:id", server.getPayment)
+
+	// Scheduled transfers
+	authRoutes.POST("/scheduled-transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled-transfers/:id", server.getScheduledTransfer)
+	authRoutes.PUT("/scheduled-transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled-transfers/:id", server.cancelScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers/:id/runs", server.listScheduledTransferRuns)
 
 	server.router = router
+	server.httpServer.Handler = router
 }
@@ -0,0 +1,342 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errScheduledTransferNotOwned = newClientError("scheduled transfer doesn't belong to the authenticated user")
+
+// scheduledTransfersSort is the only ordering of the scheduled transfers and
+// runs listings.
+const scheduledTransfersSort = "id:asc"
+
+// Scheduled transfers only move money between accounts in the same currency,
+// the exchange rate at the time of each run isn't known in advance. The
+// first run is the first occurrence of the schedule after StartAt, which
+// defaults to now.
+type createScheduledTransferRequest struct {
+	FromAccountID int64      `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64      `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64      `json:"amount" binding:"required,gt=0"`
+	Currency      string     `json:"currency" binding:"required,currency"`
+	Schedule      string     `json:"schedule" binding:"required"`
+	StartAt       *time.Time `json:"start_at"`
+	EndAt         *time.Time `json:"end_at"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	startAt := time.Now()
+	if req.StartAt != nil {
+		startAt = *req.StartAt
+	}
+
+	nextRunAt, endAt, err := firstScheduledRun(req.Schedule, startAt, req.EndAt)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	fromAccount, valid := server.getValidAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canOperateAccount(authPayload, *fromAccount) {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
+		return
+	}
+
+	if _, valid := server.getValidAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	scheduled, err := server.store.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Schedule:      req.Schedule,
+		NextRunAt:     nextRunAt,
+		EndAt:         endAt,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, scheduled)
+}
+
+// firstScheduledRun returns the first occurrence of schedule after after and
+// checks it happens before the optional end.
+func firstScheduledRun(schedule string, after time.Time, end *time.Time) (time.Time, sql.NullTime, error) {
+	next, err := util.NextScheduledTime(schedule, after)
+	if err != nil {
+		return next, sql.NullTime{}, fmt.Errorf("%w: %v", db.ErrInvalidSchedule, err)
+	}
+
+	if end == nil {
+		return next, sql.NullTime{}, nil
+	}
+
+	if next.After(*end) {
+		return next, sql.NullTime{}, newClientError("schedule %q has no run before end_at", schedule)
+	}
+
+	return next, sql.NullTime{Time: *end, Valid: true}, nil
+}
+
+type listScheduledTransfersRequest struct {
+	pageRequest
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	after, err := decodeCursor(scheduledTransfersSort, req.Cursor)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	limit := server.pageLimit(req.pageRequest)
+	arg := db.ListScheduledTransfersParams{
+		Owner: authPayload.Username,
+		Limit: limit + 1,
+	}
+	if after != nil {
+		arg.ID = after.ID
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPage(scheduledTransfers, limit, scheduledTransfersSort, func(scheduled db.ScheduledTransfer) db.PageCursor {
+		return db.PageCursor{ID: scheduled.ID}
+	}))
+}
+
+type getScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	scheduled, ok := server.loadScheduledTransfer(ctx, req.ID, canReadScheduledTransfer)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+// Fields left out are kept. Resuming a paused scheduled transfer or changing
+// its schedule moves the next run to the first occurrence from now, the
+// occurrences missed while paused aren't made up for.
+type updateScheduledTransferRequest struct {
+	Amount   *int64     `json:"amount" binding:"omitempty,gt=0"`
+	Schedule *string    `json:"schedule" binding:"omitempty,min=1"`
+	EndAt    *time.Time `json:"end_at"`
+	Status   *string    `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	scheduled, ok := server.loadOpenScheduledTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	arg := db.EditScheduledTransferParams{
+		ID:  scheduled.ID,
+		Now: time.Now(),
+	}
+	if req.Amount != nil {
+		arg.Amount = sql.NullInt64{Int64: *req.Amount, Valid: true}
+	}
+	if req.Status != nil {
+		arg.Status = sql.NullString{String: *req.Status, Valid: true}
+	}
+	if req.Schedule != nil {
+		arg.Schedule = sql.NullString{String: *req.Schedule, Valid: true}
+	}
+	if req.EndAt != nil {
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+
+	updated, err := server.store.EditScheduledTransfer(ctx, arg)
+	if err != nil {
+		respondEditScheduledTransferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	scheduled, ok := server.loadOpenScheduledTransfer(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	cancelled, err := server.store.EditScheduledTransfer(ctx, db.EditScheduledTransferParams{
+		ID:     scheduled.ID,
+		Status: sql.NullString{String: db.ScheduledTransferCancelled, Valid: true},
+		Now:    time.Now(),
+	})
+	if err != nil {
+		respondEditScheduledTransferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cancelled)
+}
+
+type listScheduledTransferRunsRequest struct {
+	pageRequest
+}
+
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req listScheduledTransferRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	after, err := decodeCursor(scheduledTransfersSort, req.Cursor)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	scheduled, ok := server.loadScheduledTransfer(ctx, uri.ID, canReadScheduledTransfer)
+	if !ok {
+		return
+	}
+
+	limit := server.pageLimit(req.pageRequest)
+	arg := db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               limit + 1,
+	}
+	if after != nil {
+		arg.ID = after.ID
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, arg)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPage(runs, limit, scheduledTransfersSort, func(run db.ScheduledTransferRun) db.PageCursor {
+		return db.PageCursor{ID: run.ID}
+	}))
+}
+
+// respondEditScheduledTransferError answers a failed EditScheduledTransfer.
+// The scheduled transfer may have been closed since it was loaded.
+func respondEditScheduledTransferError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrScheduledTransferClosed):
+		respondError(ctx, http.StatusConflict, err)
+	case errors.Is(err, db.ErrInvalidSchedule), errors.Is(err, db.ErrNoRunBeforeEnd):
+		respondError(ctx, http.StatusBadRequest, err)
+	default:
+		respondError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// loadScheduledTransfer loads the scheduled transfer and checks the
+// authenticated user is allowed to access it, writing the error response
+// when it isn't.
+func (server *Server) loadScheduledTransfer(ctx *gin.Context, id int64, allowed func(*token.Payload, db.ScheduledTransfer) bool) (*db.ScheduledTransfer, bool) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
+			return nil, false
+		}
+
+		respondError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !allowed(authPayload, scheduled) {
+		respondError(ctx, http.StatusForbidden, errScheduledTransferNotOwned)
+		return nil, false
+	}
+
+	return &scheduled, true
+}
+
+// loadOpenScheduledTransfer loads a scheduled transfer the authenticated user
+// can change. Completed and cancelled ones can't be changed anymore.
+func (server *Server) loadOpenScheduledTransfer(ctx *gin.Context, id int64) (*db.ScheduledTransfer, bool) {
+	scheduled, ok := server.loadScheduledTransfer(ctx, id, canManageScheduledTransfer)
+	if !ok {
+		return nil, false
+	}
+
+	if scheduled.Status != db.ScheduledTransferActive && scheduled.Status != db.ScheduledTransferPaused {
+		err := newClientError("scheduled transfer [%d] is %s", scheduled.ID, scheduled.Status)
+		respondError(ctx, http.StatusConflict, err)
+		return nil, false
+	}
+
+	return scheduled, true
+}
@@ -1,10 +1,17 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/exchange"
+	"github/leoflalv/bank-api/payment"
 	"github/leoflalv/bank-api/token"
 	"github/leoflalv/bank-api/util"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,26 +21,77 @@ type Server struct {
 	config       util.Config
 	store        db.Store
 	tokenManager token.Manager
+	revocations  *tokenRevocationCache
+	rates        exchange.ExchangeRateProvider
+	currencies   *currencyRegistry
+	rail         payment.PaymentRail
 	router       *gin.Engine
+	httpServer   *http.Server
 }
 
-// NewServer creates a new HTTP server and setup routing.
-func NewServer(config util.Config, store db.Store) (*Server, error) {
+// NewServer creates a new HTTP server and setup routing. The payment rail is
+// shared with the payment settler, so it is built by the caller.
+func NewServer(config util.Config, store db.Store, rail payment.PaymentRail) (*Server, error) {
 	tokenManager, err := token.NewPasetoManager(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token manager: %w", err)
 	}
 
-	server := &Server{store: store, tokenManager: tokenManager, config: config}
+	// Without a rates file only transfers between accounts in the same
+	// currency can go through.
+	var rates exchange.ExchangeRateProvider = exchange.NewStaticProvider(util.USD, nil, time.Time{})
+	if config.ExchangeRatesFile != "" {
+		rates, err = exchange.NewFileProvider(config.ExchangeRatesFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create exchange rate provider: %w", err)
+		}
+	}
+
+	currencies := newCurrencyRegistry(store)
+	if err := currencies.refresh(context.Background()); err != nil {
+		return nil, fmt.Errorf("cannot load currencies: %w", err)
+	}
+
+	server := &Server{
+		store:        store,
+		tokenManager: tokenManager,
+		revocations:  newTokenRevocationCache(store, config.RevocationCacheTTL),
+		rates:        rates,
+		currencies:   currencies,
+		rail:         rail,
+		config:       config,
+		httpServer: &http.Server{
+			ReadTimeout:       config.HTTPReadTimeout,
+			ReadHeaderTimeout: config.HTTPReadHeaderTimeout,
+			WriteTimeout:      config.HTTPWriteTimeout,
+			IdleTimeout:       config.HTTPIdleTimeout,
+		},
+	}
 
 	return server, nil
 }
 
-// Start runs the HTTP server on a specific address.
+// Start runs the HTTP server on a specific address until Shutdown is called.
 func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+// Serve accepts the connections coming to listener until Shutdown is called.
+// Being shut down isn't an error, Serve returns nil then.
+func (server *Server) Serve(listener net.Listener) error {
+	err := server.httpServer.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// Shutdown stops accepting connections and waits for the requests in flight
+// to finish, or for ctx to be done, whichever comes first.
+func (server *Server) Shutdown(ctx context.Context) error {
+	return server.httpServer.Shutdown(ctx)
 }
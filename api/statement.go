@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/statement"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statementRequest selects the entries created in [From, To). From defaults
+// to the account creation and To to now.
+type statementRequest struct {
+	From   time.Time `form:"from"`
+	To     time.Time `form:"to"`
+	Format string    `form:"format" binding:"omitempty,oneof=csv jsonl ofx"`
+}
+
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req statementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getReadableAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = statement.FormatCSV
+	}
+
+	arg := db.AccountStatementParams{
+		AccountID: account.ID,
+		From:      req.From,
+		To:        req.To,
+	}
+	if arg.From.IsZero() {
+		arg.From = account.CreatedAt
+	}
+	if arg.To.IsZero() {
+		arg.To = time.Now()
+	}
+
+	if !arg.From.Before(arg.To) {
+		respondError(ctx, http.StatusBadRequest, newClientError("from must be before to"))
+		return
+	}
+
+	w, err := statement.NewWriter(format, ctx.Writer, server.currencies.exponent(account.Currency))
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	filename := fmt.Sprintf("account-%d-%s-%s.%s", account.ID, arg.From.UTC().Format("20060102"), arg.To.UTC().Format("20060102"), format)
+	ctx.Header("Content-Type", statement.ContentType(format))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	err = server.store.WriteAccountStatement(ctx, arg, w)
+	if err == nil {
+		return
+	}
+
+	// Once the statement started streaming the status is sent, the client
+	// only sees a truncated body.
+	if ctx.Writer.Written() {
+		slog.ErrorContext(ctx, "statement interrupted", "account_id", account.ID, "error", err)
+		return
+	}
+
+	ctx.Header("Content-Type", "")
+	ctx.Header("Content-Disposition", "")
+	if errors.Is(err, db.ErrRecordNotFound) {
+		respondError(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	respondError(ctx, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestStatement returns a store stub writing a statement with entries to
+// the writer it receives, then failing with err.
+func writeTestStatement(account db.Account, entries []db.Entry, err error) func(ctx context.Context, arg db.AccountStatementParams, w db.StatementWriter) error {
+	return func(_ context.Context, arg db.AccountStatementParams, w db.StatementWriter) error {
+		statement := db.AccountStatement{
+			Account:        account,
+			From:           arg.From,
+			To:             arg.To,
+			OpeningBalance: account.Balance,
+			ClosingBalance: account.Balance,
+		}
+		if err := w.Open(statement); err != nil {
+			return err
+		}
+
+		balance := statement.OpeningBalance
+		for _, entry := range entries {
+			balance += entry.Amount
+			if err := w.Entry(db.StatementEntry{Entry: entry, Balance: balance}); err != nil {
+				return err
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		statement.ClosingBalance = balance
+		return w.Close(statement)
+	}
+}
+
+func TestGetAccountStatementAPI(t *testing.T) {
+	user, _ := randomUser()
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+	account.Balance = 1000
+	account.CreatedAt = time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: -250, CreatedAt: time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, AccountID: account.ID, Amount: 125, CreatedAt: time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	manyEntries := make([]db.Entry, 500)
+	for i := range manyEntries {
+		manyEntries[i] = db.Entry{ID: int64(i + 1), AccountID: account.ID, Amount: 1, CreatedAt: entries[0].CreatedAt}
+	}
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	query := url.Values{
+		"from": {from.Format(time.RFC3339)},
+		"to":   {to.Format(time.RFC3339)},
+	}
+
+	arg := db.AccountStatementParams{
+		AccountID: account.ID,
+		From:      from,
+		To:        to,
+	}
+
+	withQuery := func(key, value string) url.Values {
+		values := url.Values{}
+		for k, v := range query {
+			values[k] = v
+		}
+		values.Set(key, value)
+		return values
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenManager token.Manager)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "CSV",
+			query: query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any()).Times(1).
+					DoAndReturn(writeTestStatement(account, entries, nil))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Equal(t, fmt.Sprintf(`attachment; filename="account-%d-20240101-20240201.csv"`, account.ID), recorder.Header().Get("Content-Disposition"))
+				require.Equal(t, strings.Join([]string{
+					"type,entry_id,date,amount,balance",
+					"opening,,2024-01-01T00:00:00.000Z,,10.00",
+					"entry,1,2024-01-02T00:00:00.000Z,-2.50,7.50",
+					"entry,2,2024-01-03T00:00:00.000Z,1.25,8.75",
+					"closing,,2024-02-01T00:00:00.000Z,,8.75",
+					"",
+				}, "\n"), recorder.Body.String())
+			},
+		},
+		{
+			name:  "JSONL",
+			query: withQuery("format", "jsonl"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any()).Times(1).
+					DoAndReturn(writeTestStatement(account, entries, nil))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/jsonl; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Len(t, strings.Split(strings.TrimSuffix(recorder.Body.String(), "\n"), "\n"), 4)
+			},
+		},
+		{
+			name:  "OFX",
+			query: withQuery("format", "ofx"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any()).Times(1).
+					DoAndReturn(writeTestStatement(account, entries, nil))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/x-ofx", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "<BALAMT>8.75</BALAMT>")
+			},
+		},
+		{
+			name:  "DefaultPeriod",
+			query: url.Values{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AccountStatementParams, w db.StatementWriter) error {
+						require.Equal(t, account.CreatedAt, arg.From)
+						require.WithinDuration(t, time.Now(), arg.To, time.Second)
+						return writeTestStatement(account, nil, nil)(ctx, arg, w)
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "BankerReadsOtherAccount",
+			query: query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any()).Times(1).
+					DoAndReturn(writeTestStatement(account, entries, nil))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "NotOwner",
+			query: query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "intruder", util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "NoAuthorization",
+			query: query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "AccountNotFound",
+			query: query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidFormat",
+			query: withQuery("format", "xlsx"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidDateRange",
+			query: withQuery("to", from.Add(-time.Hour).Format(time.RFC3339)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any()).Times(1).
+					DoAndReturn(writeTestStatement(account, entries, sql.ErrConnDone))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Empty(t, recorder.Header().Get("Content-Disposition"))
+			},
+		},
+		{
+			name:  "ErrorWhileStreaming",
+			query: query,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WriteAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any()).Times(1).
+					DoAndReturn(writeTestStatement(account, manyEntries, sql.ErrConnDone))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.NotContains(t, recorder.Body.String(), "closing")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement?%s", account.ID, tc.query.Encode())
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenManager)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type renewAccessTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type renewAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+func (server *Server) renewAccessToken(ctx *gin.Context) {
+	var req renewAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	refreshPayload, err := server.tokenManager.VerifyToken(req.RefreshToken, token.RefreshToken)
+	if err != nil {
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	revoked, err := server.revocations.isRevoked(ctx, refreshPayload)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if revoked {
+		respondError(ctx, http.StatusUnauthorized, errRevokedToken)
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.Id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if session.IsBlocked {
+		err := newClientError("blocked session")
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if session.Username != refreshPayload.Username {
+		err := newClientError("session doesn't belong to user %s", refreshPayload.Username)
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if !util.CheckToken(req.RefreshToken, session.RefreshTokenHash) {
+		err := newClientError("mismatched session token")
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		err := newClientError("expired session")
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	accessToken, accessPayload, err := server.tokenManager.CreateToken(
+		refreshPayload.Username,
+		refreshPayload.Role,
+		token.AccessToken,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := renewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"github/leoflalv/bank-api/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser()
+
+	testCases := []struct {
+		name          string
+		buildSession  func(refreshToken string, payload *token.Payload) db.Session
+		buildStubs    func(store *mockdb.MockStore, payload *token.Payload, session db.Session)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+		tokenType     token.TokenType
+		duration      time.Duration
+	}{
+		{
+			name: "Ok",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{ID: payload.Id, Username: user.Username, RefreshTokenHash: util.HashToken(refreshToken), ExpiresAt: payload.ExpiredAt}
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp renewAccessTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.NotEmpty(t, resp.AccessToken)
+				require.NotZero(t, resp.AccessTokenExpiresAt)
+			},
+			tokenType: token.RefreshToken,
+			duration:  time.Hour,
+		},
+		{
+			name: "ExpiredToken",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{}
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			tokenType: token.RefreshToken,
+			duration:  -time.Hour,
+		},
+		{
+			name: "AccessToken",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{ID: payload.Id, Username: user.Username, RefreshTokenHash: util.HashToken(refreshToken), ExpiresAt: payload.ExpiredAt}
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			tokenType: token.AccessToken,
+			duration:  time.Hour,
+		},
+		{
+			name: "SessionNotFound",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{}
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+			tokenType: token.RefreshToken,
+			duration:  time.Hour,
+		},
+		{
+			name: "BlockedSession",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{ID: payload.Id, Username: user.Username, RefreshTokenHash: util.HashToken(refreshToken), ExpiresAt: payload.ExpiredAt, IsBlocked: true}
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			tokenType: token.RefreshToken,
+			duration:  time.Hour,
+		},
+		{
+			name: "PlaintextToken",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{ID: payload.Id, Username: user.Username, RefreshTokenHash: refreshToken, ExpiresAt: payload.ExpiredAt}
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			tokenType: token.RefreshToken,
+			duration:  time.Hour,
+		},
+		{
+			name: "MismatchedToken",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{ID: payload.Id, Username: user.Username, RefreshTokenHash: util.HashToken("other"), ExpiresAt: payload.ExpiredAt}
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			tokenType: token.RefreshToken,
+			duration:  time.Hour,
+		},
+		{
+			name: "InternalError",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{}
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+			tokenType: token.RefreshToken,
+			duration:  time.Hour,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newMockServer(t, store)
+			SetupRoutes(server)
+
+			refreshToken, payload, err := server.tokenManager.CreateToken(user.Username, util.DepositorRole, tc.tokenType, tc.duration)
+			require.NoError(t, err)
+
+			tc.buildStubs(store, payload, tc.buildSession(refreshToken, payload))
+			recorder := httptest.NewRecorder()
+
+			url := "/tokens/renew_access"
+
+			data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
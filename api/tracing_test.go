@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	}()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser()
+
+	var storeSpan trace.SpanContext
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, username string) (db.User, error) {
+			storeSpan = trace.SpanContextFromContext(ctx)
+			return user, nil
+		})
+
+	server := newMockServer(t, store)
+	SetupRoutes(server)
+	response := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/user/"+user.Username, nil)
+	require.NoError(t, err)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	server.router.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	require.Equal(t, "GET /user/:username", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+	require.Contains(t, span.Attributes(), attribute.String("http.route", "/user/:username"))
+
+	// The store gets the span of the request, its queries are traced as
+	// children of it.
+	require.Equal(t, span.SpanContext().SpanID(), storeSpan.SpanID())
+}
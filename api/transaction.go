@@ -1,37 +1,67 @@
 package api
 
 import (
-	"database/sql"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
-	"fmt"
 	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/exchange"
 	"github/leoflalv/bank-api/token"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func (server *Server) getValidAccount(ctx *gin.Context, accountID int64, currency string) (*db.Account, bool) {
+const (
+	errCodeInsufficientFunds        = "insufficient_funds"
+	errCodeIdempotencyKeyReused     = "idempotency_key_reused"
+	errCodeExchangeRateUnavailable  = "exchange_rate_unavailable"
+	errCodeAmountTooSmall           = "amount_too_small"
+	errCodeReversalExceedsTransfer  = "reversal_exceeds_transfer"
+	errCodeReversalOfReversal       = "reversal_of_reversal"
+	errCodeReversalOfLinkedTransfer = "reversal_of_linked_transfer"
+	errCodeReversalConsentRequired  = "reversal_consent_required"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+func (server *Server) getTransferAccount(ctx *gin.Context, accountID int64) (*db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
 			return nil, false
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return &account, true
+}
+
+func (server *Server) getValidAccount(ctx *gin.Context, accountID int64, currency string) (*db.Account, bool) {
+	account, valid := server.getTransferAccount(ctx, accountID)
+	if !valid {
 		return nil, false
 	}
 
 	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		err := newClientError("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
+		respondError(ctx, http.StatusBadRequest, err)
 		return nil, false
 	}
 
-	return &account, true
+	return account, true
 }
 
+// The amount and currency of a transfer are the source account's, the
+// destination account may hold a different currency.
 type transferRequest struct {
 	FromAccountId int64  `json:"from_account_id" binding:"required,min=1"`
 	ToAccountId   int64  `json:"to_account_id" binding:"required,min=1"`
@@ -42,7 +72,14 @@ type transferRequest struct {
 func (server *Server) createTransaction(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	idempotencyKey := ctx.GetHeader(idempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		err := newClientError("%s can't be longer than %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -52,13 +89,19 @@ func (server *Server) createTransaction(ctx *gin.Context) {
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from acount doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !canOperateAccount(authPayload, *fromAccount) {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
 		return
 	}
 
-	_, valid = server.getValidAccount(ctx, req.ToAccountId, req.Currency)
+	// A request with an idempotency key may be the retry of a transfer that
+	// already went through, so the funds are left for the store to check.
+	if idempotencyKey == "" && fromAccount.AvailableBalance-req.Amount < -fromAccount.OverdraftLimit {
+		respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeInsufficientFunds, db.ErrInsufficientFunds)
+		return
+	}
+
+	toAccount, valid := server.getTransferAccount(ctx, req.ToAccountId)
 	if !valid {
 		return
 	}
@@ -69,11 +112,83 @@ func (server *Server) createTransaction(ctx *gin.Context) {
 		Amount:        req.Amount,
 	}
 
+	if toAccount.Currency != fromAccount.Currency {
+		rate, err := server.rates.Rate(ctx, fromAccount.Currency, toAccount.Currency)
+		if err != nil {
+			if errors.Is(err, exchange.ErrRateNotFound) {
+				respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeExchangeRateUnavailable, err)
+				return
+			}
+
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		arg.ExchangeRate = rate.Value
+		arg.RateAt = rate.At
+	}
+
+	if idempotencyKey != "" {
+		server.createIdempotentTransaction(ctx, arg, authPayload.Username, idempotencyKey, requestHash(req))
+		return
+	}
+
 	result, err := server.store.Transaction(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		transactionErrorResponse(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, result)
 }
+
+// createIdempotentTransaction runs the transfer at most once per key, retries
+// get the response of the first attempt.
+func (server *Server) createIdempotentTransaction(ctx *gin.Context, arg db.TransactionParams, username string, key string, hash string) {
+	result, err := server.store.IdempotentTransaction(ctx, db.IdempotentTransactionParams{
+		TransactionParams: arg,
+		Username:          username,
+		Key:               key,
+		RequestHash:       hash,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyReused) {
+			respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeIdempotencyKeyReused, err)
+			return
+		}
+
+		transactionErrorResponse(ctx, err)
+		return
+	}
+
+	if result.Replayed {
+		ctx.Header(idempotentReplayedHeader, "true")
+	}
+
+	ctx.JSON(http.StatusOK, result.Transaction)
+}
+
+// transactionErrorResponse writes the response for an error returned by the
+// store while running a transfer.
+func transactionErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrAccountNotFound):
+		respondError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, db.ErrInsufficientFunds):
+		respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeInsufficientFunds, err)
+	case errors.Is(err, db.ErrAmountTooSmall):
+		respondErrorCode(ctx, http.StatusUnprocessableEntity, errCodeAmountTooSmall, err)
+	case errors.Is(err, db.ErrCurrencyMismatch):
+		respondError(ctx, http.StatusBadRequest, err)
+	default:
+		respondError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// requestHash fingerprints a transfer request to detect an idempotency key
+// being reused for a different transfer.
+func requestHash(req transferRequest) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	mockdb "github/leoflalv/bank-api/db/mock"
 	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/exchange"
 	"github/leoflalv/bank-api/token"
 	"github/leoflalv/bank-api/util"
 	"io/ioutil"
@@ -36,6 +37,16 @@ func requireBodyMatchTransaction(t *testing.T, body *bytes.Buffer, transaction d
 	require.Equal(t, transaction, transactionResult)
 }
 
+func requireBodyMatchErrorCode(t *testing.T, body *bytes.Buffer, code string) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var errResponse errorEnvelope
+	err = json.Unmarshal(data, &errResponse)
+	require.NoError(t, err)
+	require.Equal(t, code, errResponse.Error.Code)
+}
+
 func TestCreateTransactionAPI(t *testing.T) {
 	user1, _ := randomUser()
 	fromAccount := randomAccount(user1.Username)
@@ -85,7 +96,7 @@ func TestCreateTransactionAPI(t *testing.T) {
 		{
 			name: "Ok",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
@@ -106,7 +117,7 @@ func TestCreateTransactionAPI(t *testing.T) {
 		{
 			name: "BadRequest",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(0)
@@ -126,10 +137,10 @@ func TestCreateTransactionAPI(t *testing.T) {
 		{
 			name: "NotFoundAccount",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
 				store.EXPECT().Transaction(gomock.Any(), gomock.Eq(arg)).Times(0)
 			},
@@ -146,7 +157,7 @@ func TestCreateTransactionAPI(t *testing.T) {
 		{
 			name: "IncorrectCurrency",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
@@ -166,7 +177,7 @@ func TestCreateTransactionAPI(t *testing.T) {
 		{
 			name: "InternalSeverErrorAccount",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
@@ -186,7 +197,7 @@ func TestCreateTransactionAPI(t *testing.T) {
 		{
 			name: "InternalSeverErrorTransaction",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
-				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, time.Minute)
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
@@ -203,6 +214,111 @@ func TestCreateTransactionAPI(t *testing.T) {
 				"amount":          transfer.Amount,
 			},
 		},
+		{
+			name: "BankerCannotTransfer",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"currency":        fromAccount.Currency,
+				"amount":          transfer.Amount,
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"currency":        fromAccount.Currency,
+				"amount":          fromAccount.Balance + 1,
+			},
+		},
+		{
+			name: "FundsOnHold",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				heldAccount := fromAccount
+				heldAccount.AvailableBalance = 0
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(heldAccount, nil)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"currency":        fromAccount.Currency,
+				"amount":          transfer.Amount,
+			},
+		},
+		{
+			name: "InsufficientFundsTransaction",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransactionResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"currency":        fromAccount.Currency,
+				"amount":          transfer.Amount,
+			},
+		},
+		{
+			name: "AccountDeletedDuringTransaction",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.Manager) {
+				addAuthorization(t, request, tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransactionResult{}, db.ErrAccountNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"currency":        fromAccount.Currency,
+				"amount":          transfer.Amount,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -234,3 +350,292 @@ func TestCreateTransactionAPI(t *testing.T) {
 	}
 
 }
+
+func TestCreateIdempotentTransactionAPI(t *testing.T) {
+	user1, _ := randomUser()
+	fromAccount := randomAccount(user1.Username)
+	fromAccount.Currency = util.EUR
+
+	user2, _ := randomUser()
+	toAccount := randomAccount(user2.Username)
+	toAccount.Currency = util.EUR
+
+	amount := int64(4)
+	transfer := db.Transfer{
+		ID:            int64(util.RandomNumber(1, 1000)),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	}
+
+	result := db.TransactionResult{
+		Tranfer:     transfer,
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		FromEntry:   randomEntry(fromAccount.ID, -amount),
+		ToEntry:     randomEntry(toAccount.ID, amount),
+	}
+
+	key := util.RandomString(16)
+	body := gin.H{
+		"from_account_id": fromAccount.ID,
+		"to_account_id":   toAccount.ID,
+		"currency":        fromAccount.Currency,
+		"amount":          amount,
+	}
+	hash := requestHash(transferRequest{
+		FromAccountId: fromAccount.ID,
+		ToAccountId:   toAccount.ID,
+		Amount:        amount,
+		Currency:      fromAccount.Currency,
+	})
+
+	arg := db.IdempotentTransactionParams{
+		TransactionParams: db.TransactionParams{FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: amount},
+		Username:          user1.Username,
+		Key:               key,
+		RequestHash:       hash,
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstAttempt",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().IdempotentTransaction(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.IdempotentTransactionResult{Transaction: result}, nil)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+				requireBodyMatchTransaction(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "Replayed",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				// The first attempt already spent the balance, the retry must
+				// still get the original response.
+				spentAccount := fromAccount
+				spentAccount.Balance = 0
+				spentAccount.AvailableBalance = 0
+				spentAccount.OverdraftLimit = 0
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(spentAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().IdempotentTransaction(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.IdempotentTransactionResult{Transaction: result, Replayed: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				requireBodyMatchTransaction(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "KeyReused",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().IdempotentTransaction(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.IdempotentTransactionResult{}, db.ErrIdempotencyKeyReused)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeIdempotencyKeyReused)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().IdempotentTransaction(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.IdempotentTransactionResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+		},
+		{
+			name: "KeyTooLong",
+			key:  util.RandomString(maxIdempotencyKeyLength + 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().IdempotentTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().IdempotentTransaction(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.IdempotentTransactionResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/transaction", bytes.NewReader(data))
+			require.NoError(t, err)
+			req.Header.Set(idempotencyKeyHeader, tc.key)
+
+			addAuthorization(t, req, server.tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateCrossCurrencyTransactionAPI(t *testing.T) {
+	user1, _ := randomUser()
+	fromAccount := randomAccount(user1.Username)
+	fromAccount.Currency = util.USD
+
+	user2, _ := randomUser()
+	toAccount := randomAccount(user2.Username)
+	toAccount.Currency = util.EUR
+
+	cadAccount := randomAccount(user2.Username)
+	cadAccount.Currency = util.CAD
+
+	rateAt := time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)
+	amount := int64(10)
+
+	arg := db.TransactionParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		ExchangeRate:  0.5,
+		RateAt:        rateAt,
+	}
+
+	transfer := db.Transfer{
+		ID:            int64(util.RandomNumber(1, 1000)),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		ToAmount:      amount / 2,
+		ExchangeRate:  arg.ExchangeRate,
+		RateAt:        sql.NullTime{Time: rateAt, Valid: true},
+	}
+
+	result := db.TransactionResult{
+		Tranfer:     transfer,
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		FromEntry:   randomEntry(fromAccount.ID, -transfer.Amount),
+		ToEntry:     randomEntry(toAccount.ID, transfer.ToAmount),
+	}
+
+	testCases := []struct {
+		name          string
+		toAccount     db.Account
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			toAccount: toAccount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTransaction(t, recorder.Body, result)
+			},
+		},
+		{
+			name:      "RateUnavailable",
+			toAccount: cadAccount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(cadAccount.ID)).Times(1).Return(cadAccount, nil)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeExchangeRateUnavailable)
+			},
+		},
+		{
+			name:      "AmountTooSmall",
+			toAccount: toAccount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().Transaction(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransactionResult{}, db.ErrAmountTooSmall)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeAmountTooSmall)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newMockServer(t, store)
+			server.rates = exchange.NewStaticProvider(util.USD, map[string]float64{util.EUR: 0.5}, rateAt)
+			SetupRoutes(server)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   tc.toAccount.ID,
+				"currency":        fromAccount.Currency,
+				"amount":          amount,
+			})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/transaction", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenManager, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
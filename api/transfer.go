@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errTransferNotOwned = newClientError("transfer doesn't involve any account of the authenticated user")
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, ok := server.loadTransfer(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !hasPermission(authPayload, readAnyAccount) {
+		involved := false
+		for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+			account, err := server.store.GetAccount(ctx, accountID)
+			if err != nil {
+				respondError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+
+			if canReadAccount(authPayload, account) {
+				involved = true
+				break
+			}
+		}
+
+		if !involved {
+			respondError(ctx, http.StatusForbidden, errTransferNotOwned)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// loadTransfer loads the transfer, writing the error response when it can't.
+func (server *Server) loadTransfer(ctx *gin.Context, id int64) (*db.Transfer, bool) {
+	transfer, err := server.store.GetTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
+			return nil, false
+		}
+
+		respondError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return &transfer, true
+}
+
+func (server *Server) listAccountTransfers(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req historyRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	after, err := decodeCursor(req.sort(), req.Cursor)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getReadableAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	limit := server.pageLimit(req.pageRequest)
+	transfers, err := server.store.ListAccountTransfersPage(ctx, req.pageParams(account.ID, after, limit))
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPage(transfers, limit, req.sort(), func(transfer db.Transfer) db.PageCursor {
+		return db.PageCursor{ID: transfer.ID, Amount: transfer.Amount}
+	}))
+}
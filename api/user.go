@@ -1,19 +1,22 @@
 package api
 
 import (
-	"database/sql"
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
 	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/token"
 	"github/leoflalv/bank-api/util"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
+	"github.com/google/uuid"
 )
 
 type userResponse struct {
 	Username          string    `json:"username"`
+	Role              string    `json:"role"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
@@ -23,6 +26,7 @@ type userResponse struct {
 func newUserResponse(user db.User) userResponse {
 	return userResponse{
 		Username:          user.Username,
+		Role:              user.Role,
 		FullName:          user.FullName,
 		Email:             user.Email,
 		PasswordChangedAt: user.PasswordChangedAt,
@@ -37,18 +41,18 @@ type getUserRequest struct {
 func (server *Server) getUser(ctx *gin.Context) {
 	var req getUserRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -67,13 +71,13 @@ type createUserRequest struct {
 func (server *Server) createUser(ctx *gin.Context) {
 	var req createUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -86,14 +90,11 @@ func (server *Server) createUser(ctx *gin.Context) {
 
 	user, err := server.store.CreateUser(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
+		if errors.Is(err, db.ErrDuplicate) {
+			respondError(ctx, http.StatusForbidden, err)
+			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -107,45 +108,205 @@ type loginUserRequest struct {
 }
 
 type loginUserResponse struct {
-	AccessToken string       `json:"access_token"`
-	User        userResponse `json:"user"`
+	SessionID             uuid.UUID    `json:"session_id"`
+	AccessToken           string       `json:"access_token"`
+	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+	User                  userResponse `json:"user"`
 }
 
 func (server *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			loginFailures.WithLabelValues(failureUnknownUser).Inc()
+			respondError(ctx, http.StatusNotFound, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	err = util.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		loginFailures.WithLabelValues(failureWrongPassword).Inc()
+		respondError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
-	accesssToken, err := server.tokenManager.CreateToken(
+	accesssToken, accessPayload, err := server.tokenManager.CreateToken(
 		user.Username,
+		user.Role,
+		token.AccessToken,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	refreshToken, refreshPayload, err := server.tokenManager.CreateToken(
+		user.Username,
+		user.Role,
+		token.RefreshToken,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:               refreshPayload.Id,
+		Username:         user.Username,
+		RefreshTokenHash: util.HashToken(refreshToken),
+		UserAgent:        ctx.Request.UserAgent(),
+		ClientIp:         ctx.ClientIP(),
+		IsBlocked:        false,
+		ExpiresAt:        refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	resp := loginUserResponse{
-		AccessToken: accesssToken,
-		User:        newUserResponse(user),
+		SessionID:             session.ID,
+		AccessToken:           accesssToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		User:                  newUserResponse(user),
 	}
 	ctx.JSON(http.StatusOK, resp)
 }
+
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// logoutUser revokes the access token used for the request and, when the
+// refresh token is sent along, revokes it and blocks its session too.
+func (server *Server) logoutUser(ctx *gin.Context) {
+	var req logoutUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if req.RefreshToken != "" {
+		refreshPayload, err := server.tokenManager.VerifyToken(req.RefreshToken, token.RefreshToken)
+		if err != nil && !errors.Is(err, token.ErrExpiredToken) {
+			respondError(ctx, http.StatusUnauthorized, err)
+			return
+		}
+
+		if refreshPayload != nil {
+			if refreshPayload.Username != authPayload.Username {
+				err := newClientError("refresh token doesn't belong to user %s", authPayload.Username)
+				respondError(ctx, http.StatusUnauthorized, err)
+				return
+			}
+
+			err = server.store.BlockSession(ctx, db.BlockSessionParams{
+				ID:       refreshPayload.Id,
+				Username: authPayload.Username,
+			})
+			if err != nil {
+				respondError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+
+			err = server.store.RevokeToken(ctx, db.RevokeTokenParams{
+				ID:        refreshPayload.Id,
+				Username:  authPayload.Username,
+				ExpiresAt: refreshPayload.ExpiredAt,
+			})
+			if err != nil {
+				respondError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+
+			server.revocations.revoke(refreshPayload)
+		}
+	}
+
+	err := server.store.RevokeToken(ctx, db.RevokeTokenParams{
+		ID:        authPayload.Id,
+		Username:  authPayload.Username,
+		ExpiresAt: authPayload.ExpiredAt,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.revocations.revoke(authPayload)
+	ctx.JSON(http.StatusOK, struct{}{})
+}
+
+// logoutAll revokes every token issued to the authenticated user so far and
+// blocks all of its sessions.
+func (server *Server) logoutAll(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	validAfter := time.Now()
+
+	err := server.store.RevokeAllTokens(ctx, db.RevokeAllTokensParams{
+		Username:   authPayload.Username,
+		ValidAfter: validAfter,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.revocations.revokeAll(authPayload.Username, validAfter)
+	ctx.JSON(http.StatusOK, struct{}{})
+}
+
+type updateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,role"`
+}
+
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var uri getUserRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req updateUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// Tokens carry the role, the ones issued before the change must go
+	validAfter := time.Now()
+	user, err := server.store.ChangeUserRole(ctx, db.ChangeUserRoleParams{
+		Username:   uri.Username,
+		Role:       req.Role,
+		ValidAfter: validAfter,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.revocations.revokeAll(user.Username, validAfter)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
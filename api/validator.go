@@ -6,9 +6,19 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
-	if currency, ok := fieldLevel.Field().Interface().(string); ok {
-		return util.IsSupportedCurrency(currency)
+// validCurrency accepts the currencies enabled in the registry.
+func validCurrency(currencies *currencyRegistry) validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		if currency, ok := fieldLevel.Field().Interface().(string); ok {
+			return currencies.isSupported(currency)
+		}
+		return false
+	}
+}
+
+var validRole validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if role, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedRole(role)
 	}
 	return false
 }
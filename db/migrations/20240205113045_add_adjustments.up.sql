@@ -0,0 +1,22 @@
+CREATE TABLE "adjustments" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "entry_id" bigint UNIQUE NOT NULL,
+  "amount" bigint NOT NULL CHECK ("amount" <> 0),
+  "reason" varchar NOT NULL,
+  "reference" varchar,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "adjustments" ("account_id");
+
+COMMENT ON COLUMN "adjustments"."amount" IS 'can be negative or positive, never zero';
+
+COMMENT ON COLUMN "adjustments"."reference" IS 'external ticket or document backing the adjustment';
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
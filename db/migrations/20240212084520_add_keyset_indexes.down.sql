@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS "adjustments_account_id_id_idx";
+
+DROP INDEX IF EXISTS "transfers_to_account_id_amount_id_idx";
+
+DROP INDEX IF EXISTS "transfers_from_account_id_amount_id_idx";
+
+DROP INDEX IF EXISTS "transfers_to_account_id_id_idx";
+
+DROP INDEX IF EXISTS "transfers_from_account_id_id_idx";
+
+DROP INDEX IF EXISTS "entries_account_id_abs_amount_id_idx";
+
+DROP INDEX IF EXISTS "entries_account_id_id_idx";
+
+DROP INDEX IF EXISTS "accounts_owner_id_idx";
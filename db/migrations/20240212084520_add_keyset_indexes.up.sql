@@ -0,0 +1,15 @@
+CREATE INDEX "accounts_owner_id_idx" ON "accounts" ("owner", "id");
+
+CREATE INDEX "entries_account_id_id_idx" ON "entries" ("account_id", "id");
+
+CREATE INDEX "entries_account_id_abs_amount_id_idx" ON "entries" ("account_id", abs("amount"), "id");
+
+CREATE INDEX "transfers_from_account_id_id_idx" ON "transfers" ("from_account_id", "id");
+
+CREATE INDEX "transfers_to_account_id_id_idx" ON "transfers" ("to_account_id", "id");
+
+CREATE INDEX "transfers_from_account_id_amount_id_idx" ON "transfers" ("from_account_id", "amount", "id");
+
+CREATE INDEX "transfers_to_account_id_amount_id_idx" ON "transfers" ("to_account_id", "amount", "id");
+
+CREATE INDEX "adjustments_account_id_id_idx" ON "adjustments" ("account_id", "id");
@@ -0,0 +1,19 @@
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" double precision NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD COLUMN "rate_at" timestamptz;
+
+ALTER TABLE "transfers" ADD CONSTRAINT "to_amount_positive" CHECK ("to_amount" > 0);
+
+ALTER TABLE "transfers" ADD CONSTRAINT "exchange_rate_positive" CHECK ("exchange_rate" > 0);
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the destination account currency';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'destination currency units per source currency unit';
+
+COMMENT ON COLUMN "transfers"."rate_at" IS 'when the exchange rate was quoted, null for same currency transfers';
@@ -0,0 +1,22 @@
+CREATE TABLE "currencies" (
+  "code" varchar PRIMARY KEY,
+  "numeric_code" varchar UNIQUE NOT NULL,
+  "exponent" smallint NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "currencies" ADD CONSTRAINT "exponent_positive" CHECK ("exponent" >= 0);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."numeric_code" IS 'ISO 4217 numeric code';
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'number of minor unit digits, amounts are stored in minor units';
+
+INSERT INTO "currencies" ("code", "numeric_code", "exponent") VALUES
+  ('USD', '840', 2),
+  ('EUR', '978', 2),
+  ('CAD', '124', 2);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "payments";
+
+DELETE FROM "users"
+WHERE "username" = '_system'
+  AND NOT EXISTS (SELECT 1 FROM "accounts" WHERE "owner" = '_system');
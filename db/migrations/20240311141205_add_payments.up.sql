@@ -0,0 +1,44 @@
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email") VALUES
+  ('_system', '', 'Settlement', 'settlement@system.invalid');
+
+CREATE TABLE "payments" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "kind" varchar NOT NULL,
+  "amount" bigint NOT NULL CHECK ("amount" > 0),
+  "currency" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "rail_reference" varchar,
+  "failure_reason" varchar,
+  "transfer_id" bigint,
+  "refund_transfer_id" bigint,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "payments" ("account_id", "id");
+
+ALTER TABLE "payments" ADD CONSTRAINT "payments_kind_check" CHECK ("kind" IN ('deposit', 'withdrawal'));
+
+ALTER TABLE "payments" ADD CONSTRAINT "payments_status_check" CHECK ("status" IN ('pending', 'completed', 'failed'));
+
+COMMENT ON COLUMN "payments"."kind" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "payments"."status" IS 'pending, completed or failed';
+
+COMMENT ON COLUMN "payments"."rail_reference" IS 'id of the payment on the payment rail';
+
+COMMENT ON COLUMN "payments"."transfer_id" IS 'transfer between the account and the settlement account';
+
+COMMENT ON COLUMN "payments"."refund_transfer_id" IS 'transfer giving the funds back when a withdrawal fails';
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("refund_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "reversal_consents";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "reversal_of";
@@ -0,0 +1,19 @@
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint;
+
+CREATE TABLE "reversal_consents" (
+  "transfer_id" bigint PRIMARY KEY,
+  "granted_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfers" ("reversal_of");
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'transfer this one reverses, null for regular transfers';
+
+COMMENT ON COLUMN "reversal_consents"."granted_by" IS 'owner of the account credited by the transfer';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+
+ALTER TABLE "reversal_consents" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "reversal_consents" ADD FOREIGN KEY ("granted_by") REFERENCES "users" ("username");
@@ -0,0 +1,36 @@
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint;
+
+UPDATE "accounts" SET "available_balance" = "balance";
+
+ALTER TABLE "accounts" ALTER COLUMN "available_balance" SET NOT NULL;
+
+CREATE TABLE "holds" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL CHECK ("amount" > 0),
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "holds" ("account_id");
+
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" = 'active';
+
+ALTER TABLE "holds" ADD CONSTRAINT "holds_status_check" CHECK ("status" IN ('active', 'captured', 'voided', 'expired'));
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance minus the amounts reserved by active holds';
+
+COMMENT ON COLUMN "holds"."to_account_id" IS 'account credited when the hold is captured';
+
+COMMENT ON COLUMN "holds"."status" IS 'active, captured, voided or expired';
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1 @@
+CREATE INDEX "payments_pending_id_idx" ON "payments" ("id") WHERE "status" = 'pending';
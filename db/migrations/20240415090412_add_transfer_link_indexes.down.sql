@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "holds_transfer_id_idx";
+DROP INDEX IF EXISTS "payments_refund_transfer_id_idx";
+DROP INDEX IF EXISTS "payments_transfer_id_idx";
@@ -0,0 +1,5 @@
+CREATE INDEX "payments_transfer_id_idx" ON "payments" ("transfer_id");
+
+CREATE INDEX "payments_refund_transfer_id_idx" ON "payments" ("refund_transfer_id");
+
+CREATE INDEX "holds_transfer_id_idx" ON "holds" ("transfer_id");
@@ -0,0 +1,19 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+-- Entries recorded before the column existed are linked the way they used to
+-- be matched: same account, amount and transaction time as a side of the
+-- transfer.
+UPDATE "entries" e
+SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE e."created_at" = t."created_at"
+  AND (
+    (e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+    OR (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount")
+  );
+
+CREATE INDEX "entries_transfer_id_idx" ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer the entry is a side of, null for adjustments';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
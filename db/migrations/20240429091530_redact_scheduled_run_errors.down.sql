@@ -0,0 +1,2 @@
+-- The messages replaced can't be restored.
+COMMENT ON COLUMN "scheduled_transfer_runs"."error" IS NULL;
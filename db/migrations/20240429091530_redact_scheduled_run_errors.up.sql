@@ -0,0 +1,9 @@
+-- Failed runs used to store the message of any error, driver errors
+-- included, and the owner reads them. Only the reasons written for the owner
+-- are kept.
+UPDATE "scheduled_transfer_runs"
+SET "error" = 'internal error'
+WHERE "error" IS NOT NULL
+  AND "error" NOT IN ('account not found', 'insufficient funds', 'currency mismatch', 'amount too small to convert');
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."error" IS 'reason of a failed run, shown to the owner';
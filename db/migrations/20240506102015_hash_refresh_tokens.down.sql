@@ -0,0 +1,5 @@
+-- The tokens can't be restored from their hashes, the sessions can't be
+-- renewed anymore.
+UPDATE "sessions" SET "is_blocked" = true;
+
+ALTER TABLE "sessions" RENAME COLUMN "refresh_token_hash" TO "refresh_token";
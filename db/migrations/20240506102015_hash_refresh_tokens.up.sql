@@ -0,0 +1,6 @@
+-- Sessions keep the SHA-256 of the refresh token instead of the token, so a
+-- read of the table can't be used to renew access tokens.
+ALTER TABLE "sessions" RENAME COLUMN "refresh_token" TO "refresh_token_hash";
+
+UPDATE "sessions"
+SET "refresh_token_hash" = encode(sha256(convert_to("refresh_token_hash", 'UTF8')), 'hex');
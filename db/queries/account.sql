@@ -1,8 +1,8 @@
 -- name: CreateAccount :one
 insert into accounts (
-  owner, balance, currency
+  owner, balance, available_balance, currency
 ) values ( 
-  $1, $2, $3
+  $1, $2, $2, $3
 ) returning *
 ;
 
@@ -24,22 +24,22 @@ for no key update
 -- name: ListAccounts :many
 select *
 from accounts
-where owner = $1
+where owner = $1 and id > $2
 order by id
-limit $2
-offset $3
+limit $3
 ;
 
--- name: UpdateAccounts :one
+-- name: AddAccountBalance :one
 update accounts
-set balance = $2 
-where id = $1
+set balance = balance + sqlc.arg(amount),
+  available_balance = available_balance + sqlc.arg(amount)
+where id = sqlc.arg(id)
 returning *
 ;
 
--- name: AddAccountBalance :one
+-- name: AddAccountAvailableBalance :one
 update accounts
-set balance = balance + sqlc.arg(amount)
+set available_balance = available_balance + sqlc.arg(amount)
 where id = sqlc.arg(id)
 returning *
 ;
@@ -48,3 +48,37 @@ returning *
 delete from accounts
 where id = $1
 ;
+
+-- name: UpdateAccountOverdraftLimit :one
+update accounts
+set overdraft_limit = $2
+where id = $1
+returning *
+;
+
+-- name: AccountHasActivity :one
+select exists (
+  select 1
+  from entries
+  where account_id = sqlc.arg(id)
+) or exists (
+  select 1
+  from transfers
+  where from_account_id = sqlc.arg(id) or to_account_id = sqlc.arg(id)
+) as has_activity
+;
+
+-- name: CreateSettlementAccount :exec
+insert into accounts (
+  owner, balance, available_balance, currency
+) values ( 
+  '_system', 0, 0, $1
+) on conflict (owner, currency) do nothing
+;
+
+-- name: GetSettlementAccount :one
+select *
+from accounts
+where owner = '_system' and currency = $1
+limit 1
+;
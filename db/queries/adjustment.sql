@@ -0,0 +1,22 @@
+-- name: CreateAdjustment :one
+insert into adjustments (
+  account_id, entry_id, amount, reason, reference, created_by
+) values ( 
+  $1, $2, $3, $4, $5, $6
+) returning *
+;
+
+-- name: GetAdjustment :one
+select *
+from adjustments
+where id = $1
+limit 1
+;
+
+-- name: ListAdjustments :many
+select *
+from adjustments
+where account_id = $1 and id > $2
+order by id
+limit $3
+;
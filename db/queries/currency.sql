@@ -0,0 +1,12 @@
+-- name: GetCurrency :one
+select *
+from currencies
+where code = $1
+limit 1
+;
+
+-- name: ListCurrencies :many
+select *
+from currencies
+order by code
+;
@@ -8,16 +8,95 @@ limit 1
 -- name: ListEntries :many
 select *
 from entries
-where account_id = $1
+where account_id = $1 and id > $2
 order by id
-limit $2
-offset $3
+limit $3
 ;
 
 -- name: CreateEntry :one
 insert into entries 
-(account_id, amount) 
+(account_id, amount, transfer_id) 
 values 
-($1, $2) 
+($1, $2, $3) 
 returning *
 ;
+
+
+-- name: ListAccountEntriesByID :many
+select *
+from entries
+where account_id = sqlc.arg(account_id)
+  and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+  and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+  and (
+    sqlc.narg(direction)::text is null
+    or (sqlc.narg(direction)::text = 'incoming' and amount > 0)
+    or (sqlc.narg(direction)::text = 'outgoing' and amount < 0)
+  )
+  and (sqlc.narg(min_amount)::bigint is null or abs(amount) >= sqlc.narg(min_amount)::bigint)
+  and (sqlc.narg(max_amount)::bigint is null or abs(amount) <= sqlc.narg(max_amount)::bigint)
+  and id > sqlc.arg(cursor_id)
+order by id
+limit sqlc.arg(page_limit)
+;
+
+-- name: ListAccountEntriesByIDDesc :many
+select *
+from entries
+where account_id = sqlc.arg(account_id)
+  and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+  and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+  and (
+    sqlc.narg(direction)::text is null
+    or (sqlc.narg(direction)::text = 'incoming' and amount > 0)
+    or (sqlc.narg(direction)::text = 'outgoing' and amount < 0)
+  )
+  and (sqlc.narg(min_amount)::bigint is null or abs(amount) >= sqlc.narg(min_amount)::bigint)
+  and (sqlc.narg(max_amount)::bigint is null or abs(amount) <= sqlc.narg(max_amount)::bigint)
+  and id < sqlc.arg(cursor_id)
+order by id desc
+limit sqlc.arg(page_limit)
+;
+
+-- name: ListAccountEntriesByAmount :many
+select *
+from entries
+where account_id = sqlc.arg(account_id)
+  and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+  and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+  and (
+    sqlc.narg(direction)::text is null
+    or (sqlc.narg(direction)::text = 'incoming' and amount > 0)
+    or (sqlc.narg(direction)::text = 'outgoing' and amount < 0)
+  )
+  and (sqlc.narg(min_amount)::bigint is null or abs(amount) >= sqlc.narg(min_amount)::bigint)
+  and (sqlc.narg(max_amount)::bigint is null or abs(amount) <= sqlc.narg(max_amount)::bigint)
+  and (abs(amount), id) > (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+order by abs(amount), id
+limit sqlc.arg(page_limit)
+;
+
+-- name: ListAccountEntriesByAmountDesc :many
+select *
+from entries
+where account_id = sqlc.arg(account_id)
+  and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+  and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+  and (
+    sqlc.narg(direction)::text is null
+    or (sqlc.narg(direction)::text = 'incoming' and amount > 0)
+    or (sqlc.narg(direction)::text = 'outgoing' and amount < 0)
+  )
+  and (sqlc.narg(min_amount)::bigint is null or abs(amount) >= sqlc.narg(min_amount)::bigint)
+  and (sqlc.narg(max_amount)::bigint is null or abs(amount) <= sqlc.narg(max_amount)::bigint)
+  and (abs(amount), id) < (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+order by abs(amount) desc, id desc
+limit sqlc.arg(page_limit)
+;
+
+-- name: SumAccountEntriesSince :one
+select coalesce(sum(amount), 0)::bigint as total
+from entries
+where account_id = sqlc.arg(account_id)
+  and created_at >= sqlc.arg(since)
+;
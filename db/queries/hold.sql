@@ -0,0 +1,42 @@
+-- name: CreateHold :one
+insert into holds (
+  account_id, to_account_id, amount, expires_at
+) values (
+  $1, $2, $3, $4
+) returning *
+;
+
+-- name: GetHold :one
+select *
+from holds
+where id = $1
+limit 1
+;
+
+-- name: GetHoldForUpdate :one
+select *
+from holds
+where id = $1
+limit 1
+for no key update
+;
+
+-- name: UpdateHold :one
+update holds
+set
+  status = sqlc.arg(status),
+  captured_amount = sqlc.arg(captured_amount),
+  transfer_id = sqlc.narg(transfer_id),
+  updated_at = now()
+where id = sqlc.arg(id)
+returning *
+;
+
+-- name: ListExpiredHoldsForUpdate :many
+select *
+from holds
+where status = 'active' and expires_at <= sqlc.arg(now)
+order by expires_at
+limit sqlc.arg(batch_size)
+for no key update skip locked
+;
@@ -0,0 +1,15 @@
+-- name: CreateIdempotencyKey :one
+insert into idempotency_keys (
+  username, key, request_hash, response
+) values ( 
+  $1, $2, $3, $4
+) on conflict (username, key) do nothing
+returning *
+;
+
+-- name: GetIdempotencyKey :one
+select *
+from idempotency_keys
+where username = $1 and key = $2
+limit 1
+;
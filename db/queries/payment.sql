@@ -0,0 +1,45 @@
+-- name: CreatePayment :one
+insert into payments (
+  account_id, kind, amount, currency, transfer_id, created_by
+) values (
+  $1, $2, $3, $4, $5, $6
+) returning *
+;
+
+-- name: GetPayment :one
+select *
+from payments
+where id = $1
+limit 1
+;
+
+-- name: GetPaymentForUpdate :one
+select *
+from payments
+where id = $1
+limit 1
+for no key update
+;
+
+-- name: ListPendingPayments :many
+select *
+from payments
+where status = 'pending'
+  and created_at <= sqlc.arg(created_before)
+  and id > sqlc.arg(after_id)
+order by id
+limit sqlc.arg(batch_size)
+;
+
+-- name: UpdatePayment :one
+update payments
+set
+  status = sqlc.arg(status),
+  rail_reference = sqlc.narg(rail_reference),
+  failure_reason = sqlc.narg(failure_reason),
+  transfer_id = sqlc.narg(transfer_id),
+  refund_transfer_id = sqlc.narg(refund_transfer_id),
+  updated_at = now()
+where id = sqlc.arg(id)
+returning *
+;
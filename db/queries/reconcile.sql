@@ -0,0 +1,40 @@
+-- name: ListBalanceDiscrepancies :many
+select a.id as account_id, a.balance, coalesce(e.total, 0)::bigint as entries_total
+from accounts a
+left join (
+  select account_id, sum(amount) as total
+  from entries
+  group by account_id
+) e on e.account_id = a.id
+where a.balance <> coalesce(e.total, 0)
+order by a.id
+;
+
+-- name: ListUnmatchedTransfers :many
+select id, from_account_id, to_account_id, amount, to_amount, debit_entries, credit_entries
+from (
+  select
+    t.id,
+    t.from_account_id,
+    t.to_account_id,
+    t.amount,
+    t.to_amount,
+    (
+      select count(*)
+      from entries e
+      where e.transfer_id = t.id
+        and e.account_id = t.from_account_id
+        and e.amount = -t.amount
+    )::bigint as debit_entries,
+    (
+      select count(*)
+      from entries e
+      where e.transfer_id = t.id
+        and e.account_id = t.to_account_id
+        and e.amount = t.to_amount
+    )::bigint as credit_entries
+  from transfers t
+) matched
+where debit_entries <> 1 or credit_entries <> 1
+order by id
+;
@@ -0,0 +1,14 @@
+-- name: CreateReversalConsent :one
+insert into reversal_consents (
+  transfer_id, granted_by
+) values (
+  $1, $2
+) returning *
+;
+
+-- name: GetReversalConsent :one
+select *
+from reversal_consents
+where transfer_id = $1
+limit 1
+;
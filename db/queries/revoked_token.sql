@@ -0,0 +1,20 @@
+-- name: RevokeToken :exec
+insert into revoked_tokens (
+  id, username, expires_at
+) values ( 
+  $1, $2, $3
+) on conflict (id) do nothing
+;
+
+-- name: IsTokenRevoked :one
+select exists (
+  select 1
+  from revoked_tokens
+  where id = sqlc.arg(id)
+) or exists (
+  select 1
+  from users
+  where username = sqlc.arg(username)
+    and tokens_valid_after > sqlc.arg(issued_at)
+) as revoked
+;
@@ -0,0 +1,68 @@
+-- name: CreateScheduledTransfer :one
+insert into scheduled_transfers (
+  owner, from_account_id, to_account_id, amount, currency, schedule, next_run_at, end_at
+) values (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) returning *
+;
+
+-- name: GetScheduledTransfer :one
+select *
+from scheduled_transfers
+where id = $1
+limit 1
+;
+
+-- name: GetScheduledTransferForUpdate :one
+select *
+from scheduled_transfers
+where id = $1
+limit 1
+for no key update
+;
+
+-- name: ListScheduledTransfers :many
+select *
+from scheduled_transfers
+where owner = $1 and id > $2
+order by id
+limit $3
+;
+
+-- name: UpdateScheduledTransfer :one
+update scheduled_transfers
+set
+  amount = sqlc.arg(amount),
+  schedule = sqlc.arg(schedule),
+  next_run_at = sqlc.arg(next_run_at),
+  end_at = sqlc.narg(end_at),
+  status = sqlc.arg(status),
+  updated_at = now()
+where id = sqlc.arg(id)
+returning *
+;
+
+-- name: ListDueScheduledTransfersForUpdate :many
+select *
+from scheduled_transfers
+where status = 'active' and next_run_at <= sqlc.arg(now)
+order by next_run_at
+limit sqlc.arg(batch_size)
+for no key update skip locked
+;
+
+-- name: CreateScheduledTransferRun :one
+insert into scheduled_transfer_runs (
+  scheduled_transfer_id, scheduled_for, status, transfer_id, error
+) values (
+  $1, $2, $3, $4, $5
+) returning *
+;
+
+-- name: ListScheduledTransferRuns :many
+select *
+from scheduled_transfer_runs
+where scheduled_transfer_id = $1 and id > $2
+order by id
+limit $3
+;
@@ -0,0 +1,26 @@
+-- name: CreateSession :one
+insert into sessions (
+  id, username, refresh_token_hash, user_agent, client_ip, is_blocked, expires_at
+) values ( 
+  $1, $2, $3, $4, $5, $6, $7
+) returning *
+;
+
+-- name: GetSession :one
+select *
+from sessions
+where id = $1
+limit 1
+;
+
+-- name: BlockSession :exec
+update sessions
+set is_blocked = true
+where id = $1 and username = $2
+;
+
+-- name: BlockUserSessions :exec
+update sessions
+set is_blocked = true
+where username = $1
+;
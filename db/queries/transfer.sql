@@ -7,17 +7,202 @@ limit 1
 
 -- name: ListTransfers :many
 select *
-from transfers
-where from_account_id = $1 or to_account_id = $2
+from (
+  (
+    select *
+    from transfers
+    where from_account_id = $1 and id > $3
+    order by id
+    limit $4
+  )
+  union all
+  (
+    select *
+    from transfers
+    where to_account_id = $2 and from_account_id <> $1 and id > $3
+    order by id
+    limit $4
+  )
+) as transfers
 order by id
-limit $3
-offset $4
+limit $4
+;
+
+-- name: GetTransferForUpdate :one
+select *
+from transfers
+where id = $1
+limit 1
+for no key update
 ;
 
 -- name: CreateTransfer :one
 insert into transfers 
-(from_account_id, to_account_id, amount) 
+(from_account_id, to_account_id, amount, to_amount, exchange_rate, rate_at, reversal_of) 
 values 
-($1, $2, $3) 
+($1, $2, $3, $4, $5, $6, $7) 
 returning *
 ;
+
+-- name: GetReversedAmounts :one
+select coalesce(sum(amount), 0)::bigint as amount, coalesce(sum(to_amount), 0)::bigint as to_amount
+from transfers
+where reversal_of = $1
+;
+
+-- name: IsTransferLinked :one
+select exists (
+  select 1
+  from payments
+  where transfer_id = sqlc.arg(id) or refund_transfer_id = sqlc.arg(id)
+) or exists (
+  select 1
+  from holds
+  where transfer_id = sqlc.arg(id)
+) as linked
+;
+
+
+-- name: ListAccountTransfersByID :many
+select *
+from (
+  (
+    select *
+    from transfers
+    where from_account_id = sqlc.arg(account_id)
+      and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+      and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+      and (sqlc.narg(direction)::text is null or sqlc.narg(direction)::text = 'outgoing')
+      and (sqlc.narg(min_amount)::bigint is null or amount >= sqlc.narg(min_amount)::bigint)
+      and (sqlc.narg(max_amount)::bigint is null or amount <= sqlc.narg(max_amount)::bigint)
+      and id > sqlc.arg(cursor_id)
+    order by id
+    limit sqlc.arg(page_limit)
+  )
+  union all
+  (
+    select *
+    from transfers
+    where to_account_id = sqlc.arg(account_id)
+      and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+      and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+      and (sqlc.narg(direction)::text is null or sqlc.narg(direction)::text = 'incoming')
+      and (from_account_id <> sqlc.arg(account_id) or sqlc.narg(direction)::text = 'incoming')
+      and (sqlc.narg(min_amount)::bigint is null or amount >= sqlc.narg(min_amount)::bigint)
+      and (sqlc.narg(max_amount)::bigint is null or amount <= sqlc.narg(max_amount)::bigint)
+      and id > sqlc.arg(cursor_id)
+    order by id
+    limit sqlc.arg(page_limit)
+  )
+) as transfers
+order by id
+limit sqlc.arg(page_limit)
+;
+
+-- name: ListAccountTransfersByIDDesc :many
+select *
+from (
+  (
+    select *
+    from transfers
+    where from_account_id = sqlc.arg(account_id)
+      and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+      and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+      and (sqlc.narg(direction)::text is null or sqlc.narg(direction)::text = 'outgoing')
+      and (sqlc.narg(min_amount)::bigint is null or amount >= sqlc.narg(min_amount)::bigint)
+      and (sqlc.narg(max_amount)::bigint is null or amount <= sqlc.narg(max_amount)::bigint)
+      and id < sqlc.arg(cursor_id)
+    order by id desc
+    limit sqlc.arg(page_limit)
+  )
+  union all
+  (
+    select *
+    from transfers
+    where to_account_id = sqlc.arg(account_id)
+      and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+      and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+      and (sqlc.narg(direction)::text is null or sqlc.narg(direction)::text = 'incoming')
+      and (from_account_id <> sqlc.arg(account_id) or sqlc.narg(direction)::text = 'incoming')
+      and (sqlc.narg(min_amount)::bigint is null or amount >= sqlc.narg(min_amount)::bigint)
+      and (sqlc.narg(max_amount)::bigint is null or amount <= sqlc.narg(max_amount)::bigint)
+      and id < sqlc.arg(cursor_id)
+    order by id desc
+    limit sqlc.arg(page_limit)
+  )
+) as transfers
+order by id desc
+limit sqlc.arg(page_limit)
+;
+
+-- name: ListAccountTransfersByAmount :many
+select *
+from (
+  (
+    select *
+    from transfers
+    where from_account_id = sqlc.arg(account_id)
+      and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+      and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+      and (sqlc.narg(direction)::text is null or sqlc.narg(direction)::text = 'outgoing')
+      and (sqlc.narg(min_amount)::bigint is null or amount >= sqlc.narg(min_amount)::bigint)
+      and (sqlc.narg(max_amount)::bigint is null or amount <= sqlc.narg(max_amount)::bigint)
+      and (amount, id) > (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+    order by amount, id
+    limit sqlc.arg(page_limit)
+  )
+  union all
+  (
+    select *
+    from transfers
+    where to_account_id = sqlc.arg(account_id)
+      and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+      and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+      and (sqlc.narg(direction)::text is null or sqlc.narg(direction)::text = 'incoming')
+      and (from_account_id <> sqlc.arg(account_id) or sqlc.narg(direction)::text = 'incoming')
+      and (sqlc.narg(min_amount)::bigint is null or amount >= sqlc.narg(min_amount)::bigint)
+      and (sqlc.narg(max_amount)::bigint is null or amount <= sqlc.narg(max_amount)::bigint)
+      and (amount, id) > (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+    order by amount, id
+    limit sqlc.arg(page_limit)
+  )
+) as transfers
+order by amount, id
+limit sqlc.arg(page_limit)
+;
+
+-- name: ListAccountTransfersByAmountDesc :many
+select *
+from (
+  (
+    select *
+    from transfers
+    where from_account_id = sqlc.arg(account_id)
+      and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+      and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+      and (sqlc.narg(direction)::text is null or sqlc.narg(direction)::text = 'outgoing')
+      and (sqlc.narg(min_amount)::bigint is null or amount >= sqlc.narg(min_amount)::bigint)
+      and (sqlc.narg(max_amount)::bigint is null or amount <= sqlc.narg(max_amount)::bigint)
+      and (amount, id) < (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+    order by amount desc, id desc
+    limit sqlc.arg(page_limit)
+  )
+  union all
+  (
+    select *
+    from transfers
+    where to_account_id = sqlc.arg(account_id)
+      and (sqlc.narg(from_time)::timestamptz is null or created_at >= sqlc.narg(from_time)::timestamptz)
+      and (sqlc.narg(to_time)::timestamptz is null or created_at < sqlc.narg(to_time)::timestamptz)
+      and (sqlc.narg(direction)::text is null or sqlc.narg(direction)::text = 'incoming')
+      and (from_account_id <> sqlc.arg(account_id) or sqlc.narg(direction)::text = 'incoming')
+      and (sqlc.narg(min_amount)::bigint is null or amount >= sqlc.narg(min_amount)::bigint)
+      and (sqlc.narg(max_amount)::bigint is null or amount <= sqlc.narg(max_amount)::bigint)
+      and (amount, id) < (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+    order by amount desc, id desc
+    limit sqlc.arg(page_limit)
+  )
+) as transfers
+order by amount desc, id desc
+limit sqlc.arg(page_limit)
+;
@@ -13,3 +13,15 @@ where username = $1
 limit 1
 ;
 
+-- name: RevokeUserTokens :exec
+update users
+set tokens_valid_after = sqlc.arg(valid_after)
+where username = sqlc.arg(username)
+;
+
+-- name: UpdateUserRole :one
+update users
+set role = $2
+where username = $1
+returning *
+;
@@ -9,11 +9,58 @@ import (
 	"context"
 )
 
+const accountHasActivity = `-- name: AccountHasActivity :one
+select exists (
+  select 1
+  from entries
+  where account_id = $1
+) or exists (
+  select 1
+  from transfers
+  where from_account_id = $1 or to_account_id = $1
+) as has_activity
+`
+
+func (q *Queries) AccountHasActivity(ctx context.Context, id int64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, accountHasActivity, id)
+	var has_activity bool
+	err := row.Scan(&has_activity)
+	return has_activity, err
+}
+
+const addAccountAvailableBalance = `-- name: AddAccountAvailableBalance :one
+update accounts
+set available_balance = available_balance + $1
+where id = $2
+returning id, owner, balance, currency, created_at, overdraft_limit, available_balance
+`
+
+type AddAccountAvailableBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountAvailableBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
 const addAccountBalance = `-- name: AddAccountBalance :one
 update accounts
-set balance = balance + $1
+set balance = balance + $1,
+  available_balance = available_balance + $1
 where id = $2
-returning id, owner, balance, currency, created_at
+returning id, owner, balance, currency, created_at, overdraft_limit, available_balance
 `
 
 type AddAccountBalanceParams struct {
@@ -30,16 +77,18 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
 insert into accounts (
-  owner, balance, currency
+  owner, balance, available_balance, currency
 ) values ( 
-  $1, $2, $3
-) returning id, owner, balance, currency, created_at
+  $1, $2, $2, $3
+) returning id, owner, balance, currency, created_at, overdraft_limit, available_balance
 `
 
 type CreateAccountParams struct {
@@ -57,10 +106,25 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
+const createSettlementAccount = `-- name: CreateSettlementAccount :exec
+insert into accounts (
+  owner, balance, available_balance, currency
+) values ( 
+  '_system', 0, 0, $1
+) on conflict (owner, currency) do nothing
+`
+
+func (q *Queries) CreateSettlementAccount(ctx context.Context, currency string) error {
+	_, err := q.db.ExecContext(ctx, createSettlementAccount, currency)
+	return err
+}
+
 const deleteAccount = `-- name: DeleteAccount :exec
 delete from accounts
 where id = $1
@@ -72,7 +136,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-select id, owner, balance, currency, created_at
+select id, owner, balance, currency, created_at, overdraft_limit, available_balance
 from accounts
 where id = $1
 limit 1
@@ -87,12 +151,14 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-select id, owner, balance, currency, created_at
+select id, owner, balance, currency, created_at, overdraft_limit, available_balance
 from accounts
 where id = $1
 limit 1
@@ -108,27 +174,50 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const getSettlementAccount = `-- name: GetSettlementAccount :one
+select id, owner, balance, currency, created_at, overdraft_limit, available_balance
+from accounts
+where owner = '_system' and currency = $1
+limit 1
+`
+
+func (q *Queries) GetSettlementAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getSettlementAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-select id, owner, balance, currency, created_at
+select id, owner, balance, currency, created_at, overdraft_limit, available_balance
 from accounts
-where owner = $1
+where owner = $1 and id > $2
 order by id
-limit $2
-offset $3
+limit $3
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Owner string `json:"owner"`
+	ID    int64  `json:"id"`
+	Limit int32  `json:"limit"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.ID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -142,6 +231,8 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.AvailableBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -156,20 +247,20 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccounts = `-- name: UpdateAccounts :one
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
 update accounts
-set balance = $2 
+set overdraft_limit = $2
 where id = $1
-returning id, owner, balance, currency, created_at
+returning id, owner, balance, currency, created_at, overdraft_limit, available_balance
 `
 
-type UpdateAccountsParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type UpdateAccountOverdraftLimitParams struct {
+	ID             int64 `json:"id"`
+	OverdraftLimit int64 `json:"overdraft_limit"`
 }
 
-func (q *Queries) UpdateAccounts(ctx context.Context, arg UpdateAccountsParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, updateAccounts, arg.ID, arg.Balance)
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountOverdraftLimit, arg.ID, arg.OverdraftLimit)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -177,6 +268,8 @@ func (q *Queries) UpdateAccounts(ctx context.Context, arg UpdateAccountsParams)
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
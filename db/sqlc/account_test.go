@@ -16,7 +16,7 @@ func createRandomAccount(t *testing.T) Account {
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomNumber(0, 1000),
-		Currency: util.RandomCurrency(),
+		Currency: randomCurrency(t),
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -26,13 +26,36 @@ func createRandomAccount(t *testing.T) Account {
 
 }
 
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
+	return createAccountWithBalance(t, currency, util.RandomNumber(0, 1000))
+}
+
+// createFundedAccount creates an account with enough balance to send the
+// transfers of the tests, which can't overdraw it.
+func createFundedAccount(t *testing.T, currency string) Account {
+	return createAccountWithBalance(t, currency, util.RandomNumber(100, 1000))
+}
+
+func createAccountWithBalance(t *testing.T, currency string, balance int64) Account {
+	user := createRandomUser()
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
 func TestCreateAccount(t *testing.T) {
 	user := createRandomUser()
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomNumber(0, 1000),
-		Currency: util.RandomCurrency(),
+		Currency: randomCurrency(t),
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -41,6 +64,7 @@ func TestCreateAccount(t *testing.T) {
 
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
+	require.Equal(t, arg.Balance, account.AvailableBalance)
 	require.Equal(t, arg.Currency, account.Currency)
 
 	require.NotZero(t, account.ID)
@@ -60,25 +84,6 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
-func TestUpdateAccount(t *testing.T) {
-	account1 := createRandomAccount(t)
-
-	arg := UpdateAccountsParams{
-		ID:      account1.ID,
-		Balance: util.RandomNumber(1, 1000),
-	}
-
-	account2, err := testQueries.UpdateAccounts(context.Background(), arg)
-	require.NoError(t, err)
-	require.NotEmpty(t, account2)
-
-	require.Equal(t, account1.ID, account2.ID)
-	require.Equal(t, account1.Owner, account2.Owner)
-	require.Equal(t, arg.Balance, account2.Balance)
-	require.Equal(t, account1.Currency, account2.Currency)
-	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
-}
-
 func TestDeleteAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 	err := testQueries.DeleteAccount(context.Background(), account1.ID)
@@ -90,6 +95,26 @@ func TestDeleteAccount(t *testing.T) {
 	require.Empty(t, account2)
 }
 
+func TestAccountHasActivity(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	hasActivity, err := testQueries.AccountHasActivity(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.False(t, hasActivity)
+
+	createRandomEntry(account1)
+	hasActivity, err = testQueries.AccountHasActivity(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.True(t, hasActivity)
+
+	createRandomTransfer(account2, account3)
+	hasActivity, err = testQueries.AccountHasActivity(context.Background(), account3.ID)
+	require.NoError(t, err)
+	require.True(t, hasActivity)
+}
+
 func TestListAccounts(t *testing.T) {
 	var lastAccount Account
 	for i := 0; i < 10; i++ {
@@ -97,9 +122,9 @@ func TestListAccounts(t *testing.T) {
 	}
 
 	arg := ListAccountsParams{
-		Owner:  lastAccount.Owner,
-		Limit:  5,
-		Offset: 0,
+		Owner: lastAccount.Owner,
+		ID:    0,
+		Limit: 5,
 	}
 
 	accounts, err := testQueries.ListAccounts(context.Background(), arg)
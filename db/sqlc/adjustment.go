@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+type AdjustBalanceParams struct {
+	AccountID int64          `json:"account_id"`
+	Amount    int64          `json:"amount"`
+	Reason    string         `json:"reason"`
+	Reference sql.NullString `json:"reference"`
+	CreatedBy string         `json:"created_by"`
+}
+
+type AdjustBalanceResult struct {
+	Adjustment Adjustment `json:"adjustment"`
+	Account    Account    `json:"account"`
+	Entry      Entry      `json:"entry"`
+}
+
+// AdjustBalance corrects an account balance outside of a transfer. The entry,
+// the adjustment record and the new balance are committed together so the
+// ledger always explains the balance.
+func (store *SQLStore) AdjustBalance(ctx context.Context, arg AdjustBalanceParams) (AdjustBalanceResult, error) {
+	var result AdjustBalanceResult
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return accountError(err)
+		}
+
+		if arg.Amount < 0 && account.AvailableBalance+arg.Amount < -account.OverdraftLimit {
+			return ErrInsufficientFunds
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: arg.AccountID,
+			Amount:    arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Adjustment, err = q.CreateAdjustment(ctx, CreateAdjustmentParams{
+			AccountID: arg.AccountID,
+			EntryID:   result.Entry.ID,
+			Amount:    arg.Amount,
+			Reason:    arg.Reason,
+			Reference: arg.Reference,
+			CreatedBy: arg.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setAccountBalance brings the account to balance through an adjustment so the
+// ledger stays consistent with it.
+func setAccountBalance(t *testing.T, store Store, account Account, balance int64) Account {
+	result, err := store.AdjustBalance(context.Background(), AdjustBalanceParams{
+		AccountID: account.ID,
+		Amount:    balance - account.Balance,
+		Reason:    "test setup",
+		CreatedBy: account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, balance, result.Account.Balance)
+
+	return result.Account
+}
+
+func TestAdjustBalance(t *testing.T) {
+	store := NewStore(testDB)
+	account := createFundedAccount(t, randomCurrency(t))
+
+	arg := AdjustBalanceParams{
+		AccountID: account.ID,
+		Amount:    -account.Balance / 2,
+		Reason:    "chargeback",
+		Reference: sql.NullString{String: "TICKET-42", Valid: true},
+		CreatedBy: account.Owner,
+	}
+
+	result, err := store.AdjustBalance(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, account.Balance+arg.Amount, result.Account.Balance)
+
+	require.NotZero(t, result.Entry.ID)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, arg.Amount, result.Entry.Amount)
+
+	adjustment, err := testQueries.GetAdjustment(context.Background(), result.Adjustment.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, adjustment.AccountID)
+	require.Equal(t, result.Entry.ID, adjustment.EntryID)
+	require.Equal(t, arg.Amount, adjustment.Amount)
+	require.Equal(t, arg.Reason, adjustment.Reason)
+	require.Equal(t, arg.Reference, adjustment.Reference)
+	require.Equal(t, arg.CreatedBy, adjustment.CreatedBy)
+	require.WithinDuration(t, time.Now(), adjustment.CreatedAt, time.Minute)
+
+	entry, err := testQueries.GetEntry(context.Background(), adjustment.EntryID)
+	require.NoError(t, err)
+	require.Equal(t, arg.Amount, entry.Amount)
+}
+
+func TestAdjustBalanceInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	_, err := store.AdjustBalance(context.Background(), AdjustBalanceParams{
+		AccountID: account.ID,
+		Amount:    -account.Balance - 1,
+		Reason:    "chargeback",
+		CreatedBy: account.Owner,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	account2, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, account2.Balance)
+
+	adjustments, err := testQueries.ListAdjustments(context.Background(), ListAdjustmentsParams{
+		AccountID: account.ID,
+		ID:        0,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, adjustments)
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"github/leoflalv/bank-api/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	byCode := make(map[string]Currency)
+	for i, currency := range currencies {
+		if i > 0 {
+			require.Less(t, currencies[i-1].Code, currency.Code)
+		}
+		byCode[currency.Code] = currency
+	}
+
+	// the currencies seeded by the migration
+	for _, code := range []string{util.USD, util.EUR, util.CAD} {
+		currency, ok := byCode[code]
+		require.True(t, ok)
+		require.Len(t, currency.NumericCode, 3)
+		require.Equal(t, int16(2), currency.Exponent)
+	}
+}
+
+// randomCurrency picks one of the enabled currencies of the currencies table.
+func randomCurrency(t *testing.T) string {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	codes := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		if currency.Enabled {
+			codes = append(codes, currency.Code)
+		}
+	}
+	require.NotEmpty(t, codes)
+
+	return util.RandomCurrency(codes)
+}
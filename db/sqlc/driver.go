@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Open opens the database like sql.Open, with its connections wrapped so the
+// span of a query returning rows ends when the rows are closed.
+func Open(driverName, dataSourceName string) (*sql.DB, error) {
+	plain, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	driverCtx, ok := plain.Driver().(driver.DriverContext)
+	if !ok {
+		return plain, nil
+	}
+	connector, err := driverCtx.OpenConnector(dataSourceName)
+	plain.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.OpenDB(tracedConnector{connector}), nil
+}
+
+// rowsSpanKey carries the span started by tracedDBTX.QueryContext down to
+// the driver connection running the query.
+type rowsSpanKey struct{}
+
+// rowsSpan is claimed by the connection when it hands the rows over, from
+// then on the rows end the span.
+type rowsSpan struct {
+	span    trace.Span
+	claimed bool
+}
+
+type tracedConnector struct {
+	driver.Connector
+}
+
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tracedConn{conn}, nil
+}
+
+// tracedConn forwards the optional interfaces of the driver connection,
+// database/sql falls back to slower paths or refuses isolation levels when
+// they are missing.
+type tracedConn struct {
+	driver.Conn
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending, ok := ctx.Value(rowsSpanKey{}).(*rowsSpan); ok {
+		pending.claimed = true
+		return &tracedRows{Rows: rows, span: pending.span}, nil
+	}
+	return rows, nil
+}
+
+func (c tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		return nil, errors.New("driver doesn't support transaction options")
+	}
+	return c.Conn.Begin()
+}
+
+func (c tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c tracedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// tracedRows ends the span of its query when closed, with the error that
+// stopped the reading if any.
+type tracedRows struct {
+	driver.Rows
+	span trace.Span
+}
+
+func (r *tracedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && err != io.EOF {
+		recordSpanError(r.span, err)
+	}
+	return err
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	recordSpanError(r.span, err)
+	r.span.End()
+	return err
+}
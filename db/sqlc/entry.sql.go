@@ -7,35 +7,39 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 insert into entries 
-(account_id, amount) 
+(account_id, amount, transfer_id) 
 values 
-($1, $2) 
-returning id, account_id, amount, created_at
+($1, $2, $3) 
+returning id, account_id, amount, created_at, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-select id, account_id, amount, created_at
+select id, account_id, amount, created_at, transfer_id
 from entries
 where id = $1
 limit 1
@@ -49,27 +53,299 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
-const listEntries = `-- name: ListEntries :many
-select id, account_id, amount, created_at
+const listAccountEntriesByAmount = `-- name: ListAccountEntriesByAmount :many
+select id, account_id, amount, created_at, transfer_id
+from entries
+where account_id = $1
+  and ($2::timestamptz is null or created_at >= $2::timestamptz)
+  and ($3::timestamptz is null or created_at < $3::timestamptz)
+  and (
+    $4::text is null
+    or ($4::text = 'incoming' and amount > 0)
+    or ($4::text = 'outgoing' and amount < 0)
+  )
+  and ($5::bigint is null or abs(amount) >= $5::bigint)
+  and ($6::bigint is null or abs(amount) <= $6::bigint)
+  and (abs(amount), id) > ($7::bigint, $8::bigint)
+order by abs(amount), id
+limit $9
+`
+
+type ListAccountEntriesByAmountParams struct {
+	AccountID    int64          `json:"account_id"`
+	FromTime     sql.NullTime   `json:"from_time"`
+	ToTime       sql.NullTime   `json:"to_time"`
+	Direction    sql.NullString `json:"direction"`
+	MinAmount    sql.NullInt64  `json:"min_amount"`
+	MaxAmount    sql.NullInt64  `json:"max_amount"`
+	CursorAmount int64          `json:"cursor_amount"`
+	CursorID     int64          `json:"cursor_id"`
+	PageLimit    int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountEntriesByAmount(ctx context.Context, arg ListAccountEntriesByAmountParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntriesByAmount,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorAmount,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountEntriesByAmountDesc = `-- name: ListAccountEntriesByAmountDesc :many
+select id, account_id, amount, created_at, transfer_id
+from entries
+where account_id = $1
+  and ($2::timestamptz is null or created_at >= $2::timestamptz)
+  and ($3::timestamptz is null or created_at < $3::timestamptz)
+  and (
+    $4::text is null
+    or ($4::text = 'incoming' and amount > 0)
+    or ($4::text = 'outgoing' and amount < 0)
+  )
+  and ($5::bigint is null or abs(amount) >= $5::bigint)
+  and ($6::bigint is null or abs(amount) <= $6::bigint)
+  and (abs(amount), id) < ($7::bigint, $8::bigint)
+order by abs(amount) desc, id desc
+limit $9
+`
+
+type ListAccountEntriesByAmountDescParams struct {
+	AccountID    int64          `json:"account_id"`
+	FromTime     sql.NullTime   `json:"from_time"`
+	ToTime       sql.NullTime   `json:"to_time"`
+	Direction    sql.NullString `json:"direction"`
+	MinAmount    sql.NullInt64  `json:"min_amount"`
+	MaxAmount    sql.NullInt64  `json:"max_amount"`
+	CursorAmount int64          `json:"cursor_amount"`
+	CursorID     int64          `json:"cursor_id"`
+	PageLimit    int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountEntriesByAmountDesc(ctx context.Context, arg ListAccountEntriesByAmountDescParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntriesByAmountDesc,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorAmount,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountEntriesByID = `-- name: ListAccountEntriesByID :many
+select id, account_id, amount, created_at, transfer_id
 from entries
 where account_id = $1
+  and ($2::timestamptz is null or created_at >= $2::timestamptz)
+  and ($3::timestamptz is null or created_at < $3::timestamptz)
+  and (
+    $4::text is null
+    or ($4::text = 'incoming' and amount > 0)
+    or ($4::text = 'outgoing' and amount < 0)
+  )
+  and ($5::bigint is null or abs(amount) >= $5::bigint)
+  and ($6::bigint is null or abs(amount) <= $6::bigint)
+  and id > $7
 order by id
-limit $2
-offset $3
+limit $8
+`
+
+type ListAccountEntriesByIDParams struct {
+	AccountID int64          `json:"account_id"`
+	FromTime  sql.NullTime   `json:"from_time"`
+	ToTime    sql.NullTime   `json:"to_time"`
+	Direction sql.NullString `json:"direction"`
+	MinAmount sql.NullInt64  `json:"min_amount"`
+	MaxAmount sql.NullInt64  `json:"max_amount"`
+	CursorID  int64          `json:"cursor_id"`
+	PageLimit int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountEntriesByID(ctx context.Context, arg ListAccountEntriesByIDParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntriesByID,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountEntriesByIDDesc = `-- name: ListAccountEntriesByIDDesc :many
+select id, account_id, amount, created_at, transfer_id
+from entries
+where account_id = $1
+  and ($2::timestamptz is null or created_at >= $2::timestamptz)
+  and ($3::timestamptz is null or created_at < $3::timestamptz)
+  and (
+    $4::text is null
+    or ($4::text = 'incoming' and amount > 0)
+    or ($4::text = 'outgoing' and amount < 0)
+  )
+  and ($5::bigint is null or abs(amount) >= $5::bigint)
+  and ($6::bigint is null or abs(amount) <= $6::bigint)
+  and id < $7
+order by id desc
+limit $8
+`
+
+type ListAccountEntriesByIDDescParams struct {
+	AccountID int64          `json:"account_id"`
+	FromTime  sql.NullTime   `json:"from_time"`
+	ToTime    sql.NullTime   `json:"to_time"`
+	Direction sql.NullString `json:"direction"`
+	MinAmount sql.NullInt64  `json:"min_amount"`
+	MaxAmount sql.NullInt64  `json:"max_amount"`
+	CursorID  int64          `json:"cursor_id"`
+	PageLimit int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountEntriesByIDDesc(ctx context.Context, arg ListAccountEntriesByIDDescParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntriesByIDDesc,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntries = `-- name: ListEntries :many
+select id, account_id, amount, created_at, transfer_id
+from entries
+where account_id = $1 and id > $2
+order by id
+limit $3
 `
 
 type ListEntriesParams struct {
 	AccountID int64 `json:"account_id"`
+	ID        int64 `json:"id"`
 	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.ID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +358,7 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
@@ -95,3 +372,22 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const sumAccountEntriesSince = `-- name: SumAccountEntriesSince :one
+select coalesce(sum(amount), 0)::bigint as total
+from entries
+where account_id = $1
+  and created_at >= $2
+`
+
+type SumAccountEntriesSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+func (q *Queries) SumAccountEntriesSince(ctx context.Context, arg SumAccountEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumAccountEntriesSince, arg.AccountID, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
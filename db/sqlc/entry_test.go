@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"github/leoflalv/bank-api/util"
 	"testing"
 	"time"
@@ -55,22 +56,152 @@ func TestGetEntry(t *testing.T) {
 
 func TestListEntries(t *testing.T) {
 	account := createRandomAccount(t)
+	var entries1 []Entry
 	for i := 0; i < 10; i++ {
-		createRandomEntry(account)
+		entries1 = append(entries1, createRandomEntry(account))
 	}
 
 	arg := ListEntriesParams{
 		AccountID: account.ID,
+		ID:        entries1[4].ID,
 		Limit:     5,
-		Offset:    5,
 	}
 
 	entries, err := testQueries.ListEntries(context.Background(), arg)
 	require.NoError(t, err)
 	require.Len(t, entries, 5)
 
-	for _, entry := range entries {
+	for i, entry := range entries {
 		require.NotEmpty(t, entry)
 		require.Equal(t, arg.AccountID, entry.AccountID)
+		require.Equal(t, entries1[i+5].ID, entry.ID)
 	}
 }
+
+func TestListAccountEntriesPage(t *testing.T) {
+	account := createRandomAccount(t)
+	for _, amount := range []int64{-30, 10, -20, 40, 50} {
+		_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+	}
+
+	arg := HistoryPageParams{
+		AccountID: account.ID,
+		FromTime:  sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+		Direction: sql.NullString{String: "incoming", Valid: true},
+		MinAmount: sql.NullInt64{Int64: 20, Valid: true},
+		SortBy:    SortByAmount,
+		SortDesc:  true,
+		Limit:     5,
+	}
+
+	entries, err := testQueries.ListAccountEntriesPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, int64(50), entries[0].Amount)
+	require.Equal(t, int64(40), entries[1].Amount)
+
+	arg = HistoryPageParams{
+		AccountID: account.ID,
+		Direction: sql.NullString{String: "outgoing", Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 25, Valid: true},
+		Limit:     5,
+	}
+
+	entries, err = testQueries.ListAccountEntriesPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(-20), entries[0].Amount)
+
+	arg = HistoryPageParams{
+		AccountID: account.ID,
+		ToTime:    sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+		Limit:     5,
+	}
+
+	entries, err = testQueries.ListAccountEntriesPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestListAccountEntriesPageCursor(t *testing.T) {
+	account := createRandomAccount(t)
+	for _, amount := range []int64{-30, 10, -20, 40, 50} {
+		_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name     string
+		sortBy   string
+		sortDesc bool
+		amounts  []int64
+		cursorOf func(entry Entry) PageCursor
+	}{
+		{
+			name:    "ByID",
+			sortBy:  SortByCreatedAt,
+			amounts: []int64{-30, 10, -20, 40, 50},
+		},
+		{
+			name:     "ByIDDesc",
+			sortBy:   SortByCreatedAt,
+			sortDesc: true,
+			amounts:  []int64{50, 40, -20, 10, -30},
+		},
+		{
+			name:    "ByAmount",
+			sortBy:  SortByAmount,
+			amounts: []int64{10, -20, -30, 40, 50},
+		},
+		{
+			name:     "ByAmountDesc",
+			sortBy:   SortByAmount,
+			sortDesc: true,
+			amounts:  []int64{50, 40, -30, -20, 10},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var amounts []int64
+			var after *PageCursor
+
+			for {
+				entries, err := testQueries.ListAccountEntriesPage(context.Background(), HistoryPageParams{
+					AccountID: account.ID,
+					SortBy:    tc.sortBy,
+					SortDesc:  tc.sortDesc,
+					After:     after,
+					Limit:     2,
+				})
+				require.NoError(t, err)
+				if len(entries) == 0 {
+					break
+				}
+
+				for _, entry := range entries {
+					amounts = append(amounts, entry.Amount)
+				}
+
+				last := entries[len(entries)-1]
+				after = &PageCursor{ID: last.ID, Amount: absAmount(last.Amount)}
+			}
+
+			require.Equal(t, tc.amounts, amounts)
+		})
+	}
+}
+
+func absAmount(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
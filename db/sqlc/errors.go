@@ -0,0 +1,71 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrRecordNotFound is returned when the row a query looks for doesn't exist.
+var ErrRecordNotFound = errors.New("not found")
+
+// ErrAccountNotFound is returned when an account of the operation doesn't
+// exist. It's also an ErrRecordNotFound.
+var ErrAccountNotFound = fmt.Errorf("account %w", ErrRecordNotFound)
+
+// ErrInsufficientFunds is returned when a transfer would take the available
+// balance of the source account below its overdraft limit. Funds reserved by
+// holds can't be spent.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrCurrencyMismatch is returned when the accounts of a transfer hold
+// different currencies and no exchange rate was given, or a rate was given
+// for accounts in the same currency.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ErrDuplicate is returned when a row conflicts with a unique constraint.
+var ErrDuplicate = errors.New("already exists")
+
+// ErrForeignKey is returned when a row references a missing row, or a row
+// still referenced is deleted.
+var ErrForeignKey = errors.New("related record is missing or still in use")
+
+// TranslateError turns the errors of the database driver into the typed
+// errors of this package. The original error stays wrapped, so its message is
+// kept and it can still be inspected. Errors without a translation are
+// returned as is.
+func TranslateError(err error) error {
+	if err == nil || isTranslated(err) {
+		return err
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrRecordNotFound, err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return fmt.Errorf("%w: %w", ErrDuplicate, err)
+		case "foreign_key_violation":
+			return fmt.Errorf("%w: %w", ErrForeignKey, err)
+		}
+	}
+
+	return err
+}
+
+func isTranslated(err error) bool {
+	return errors.Is(err, ErrRecordNotFound) || errors.Is(err, ErrDuplicate) || errors.Is(err, ErrForeignKey)
+}
+
+// accountError reports a missing account as ErrAccountNotFound.
+func accountError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccountNotFound
+	}
+	return err
+}
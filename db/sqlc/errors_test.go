@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	other := errors.New("connection reset")
+
+	testCases := []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{name: "NoRows", err: sql.ErrNoRows, target: ErrRecordNotFound},
+		{name: "WrappedNoRows", err: fmt.Errorf("get account: %w", sql.ErrNoRows), target: ErrRecordNotFound},
+		{name: "UniqueViolation", err: &pq.Error{Code: "23505"}, target: ErrDuplicate},
+		{name: "ForeignKeyViolation", err: &pq.Error{Code: "23503"}, target: ErrForeignKey},
+		{name: "AccountNotFound", err: ErrAccountNotFound, target: ErrRecordNotFound},
+		{name: "Untranslated", err: other, target: other},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			translated := TranslateError(tc.err)
+			require.ErrorIs(t, translated, tc.target)
+			require.ErrorIs(t, translated, tc.err)
+			require.Equal(t, translated, TranslateError(translated))
+		})
+	}
+
+	require.NoError(t, TranslateError(nil))
+
+	var pqErr *pq.Error
+	require.ErrorAs(t, TranslateError(&pq.Error{Code: "23505"}), &pqErr)
+}
+
+func TestStoreTranslatesErrors(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser()
+
+	_, err := store.GetUser(context.Background(), user.Username+"-missing")
+	require.ErrorIs(t, err, ErrRecordNotFound)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.CreateUser(context.Background(), CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: "secret",
+		FullName:       user.FullName,
+		Email:          user.Email,
+	})
+	require.ErrorIs(t, err, ErrDuplicate)
+}
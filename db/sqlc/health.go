@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// SchemaVersion is the version of the last migration in db/migrations, the
+// one the queries of this package are written against.
+const SchemaVersion int64 = 20240506102015
+
+// Ping checks the database can be reached.
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// MigrationVersion returns the version of the last migration applied to the
+// database and whether it failed halfway, as recorded by migrate.
+func (store *SQLStore) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	row := store.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`)
+	err = row.Scan(&version, &dirty)
+	return
+}
+
+// WaitForDB pings the database until it answers, waiting between attempts
+// as policy says, since the database may still be starting along with the
+// server. It returns the last error if the database doesn't answer after the
+// last retry or ctx is done first.
+func WaitForDB(ctx context.Context, store Store, policy RetryPolicy) error {
+	for attempt := 0; ; attempt++ {
+		err := store.Ping(ctx)
+		if err == nil || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(policy.backoff(attempt)):
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldVoided   = "voided"
+	HoldExpired  = "expired"
+)
+
+// ErrHoldNotActive is returned when capturing or voiding a hold that was
+// already captured, voided or released.
+var ErrHoldNotActive = errors.New("hold is not active")
+
+// ErrHoldExpired is returned when capturing a hold past its expiry, even if
+// the expiry job hasn't released it yet.
+var ErrHoldExpired = errors.New("hold has expired")
+
+// ErrCaptureExceedsHold is returned when capturing more than the hold
+// reserved.
+var ErrCaptureExceedsHold = errors.New("capture exceeds the held amount")
+
+type AuthorizeHoldParams struct {
+	AccountID   int64     `json:"account_id"`
+	ToAccountID int64     `json:"to_account_id"`
+	Amount      int64     `json:"amount"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// AuthorizeHold reserves Amount on the account until the hold is captured,
+// voided or expires. The balance doesn't change, only the available balance.
+func (store *SQLStore) AuthorizeHold(ctx context.Context, arg AuthorizeHoldParams) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		account, toAccount, err := lockAccounts(ctx, q, arg.AccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		// Holds are captured without conversion.
+		if account.Currency != toAccount.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		if account.AvailableBalance-arg.Amount < -account.OverdraftLimit {
+			return ErrInsufficientFunds
+		}
+
+		hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   arg.AccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount:      arg.Amount,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     arg.AccountID,
+			Amount: -arg.Amount,
+		})
+		return err
+	})
+
+	return hold, err
+}
+
+// CaptureHoldParams captures Amount of the hold, a zero Amount captures all of
+// it. A hold is captured once, what isn't captured is released.
+type CaptureHoldParams struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount"`
+}
+
+type CaptureHoldResult struct {
+	Hold        Hold              `json:"hold"`
+	Transaction TransactionResult `json:"transaction"`
+}
+
+// CaptureHold moves the captured amount to the account of the hold with a
+// regular transfer and releases the reservation.
+func (store *SQLStore) CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error) {
+	var result CaptureHoldResult
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		hold, err := activeHold(ctx, q, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if hold.ExpiresAt.Before(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		if _, _, err := lockAccounts(ctx, q, hold.AccountID, hold.ToAccountID); err != nil {
+			return err
+		}
+
+		// The funds were checked when authorizing, the transfer only has to
+		// take over the reservation.
+		err = releaseHold(ctx, q, hold)
+		if err != nil {
+			return err
+		}
+
+		result.Transaction, err = recordTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			ToAmount:      amount,
+			ExchangeRate:  1,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			ID:             hold.ID,
+			Status:         HoldCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transaction.Tranfer.ID, Valid: true},
+		})
+		return err
+	})
+	if err == nil {
+		countTransfer(transferKindCapture, result.Transaction)
+	}
+
+	return result, err
+}
+
+// VoidHold releases the whole reservation without moving any money.
+func (store *SQLStore) VoidHold(ctx context.Context, id int64) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		var err error
+		hold, err = activeHold(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		hold, err = closeHold(ctx, q, hold, HoldVoided)
+		return err
+	})
+
+	return hold, err
+}
+
+type ReleaseExpiredHoldsParams struct {
+	Now       time.Time `json:"now"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+// ReleaseExpiredHolds gives back the reservations of the active holds that
+// expired at Now, skipping the ones locked by a capture or void in progress.
+func (store *SQLStore) ReleaseExpiredHolds(ctx context.Context, arg ReleaseExpiredHoldsParams) ([]Hold, error) {
+	var released []Hold
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		expired, err := q.ListExpiredHoldsForUpdate(ctx, ListExpiredHoldsForUpdateParams{
+			Now:       arg.Now,
+			BatchSize: arg.BatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		released = make([]Hold, 0, len(expired))
+		for _, hold := range expired {
+			hold, err = closeHold(ctx, q, hold, HoldExpired)
+			if err != nil {
+				return err
+			}
+			released = append(released, hold)
+		}
+
+		return nil
+	})
+
+	return released, err
+}
+
+// activeHold locks the hold, which must still be active.
+func activeHold(ctx context.Context, q *Queries, id int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, id)
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != HoldActive {
+		return hold, ErrHoldNotActive
+	}
+
+	return hold, nil
+}
+
+// closeHold releases the reservation of a locked hold and records why.
+func closeHold(ctx context.Context, q *Queries, hold Hold, status string) (Hold, error) {
+	err := releaseHold(ctx, q, hold)
+	if err != nil {
+		return hold, err
+	}
+
+	return q.UpdateHold(ctx, UpdateHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+}
+
+// releaseHold gives the held amount back to the available balance.
+func releaseHold(ctx context.Context, q *Queries, hold Hold) error {
+	_, err := q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		ID:     hold.AccountID,
+		Amount: hold.Amount,
+	})
+	return err
+}
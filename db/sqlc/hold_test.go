@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeRandomHold(t *testing.T, store Store, amount int64, expiresAt time.Time) (Hold, Account, Account) {
+	account1 := createFundedAccount(t, randomCurrency(t))
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	hold, err := store.AuthorizeHold(context.Background(), AuthorizeHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      amount,
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldActive, hold.Status)
+	require.Equal(t, amount, hold.Amount)
+
+	// only the available balance changes until the hold is captured
+	heldAccount, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, heldAccount.Balance)
+	require.Equal(t, account1.AvailableBalance-amount, heldAccount.AvailableBalance)
+
+	return hold, account1, account2
+}
+
+func TestCaptureHold(t *testing.T) {
+	store := NewStore(testDB)
+	hold, account1, account2 := authorizeRandomHold(t, store, 50, time.Now().Add(time.Hour))
+
+	_, err := store.CaptureHold(context.Background(), CaptureHoldParams{ID: hold.ID, Amount: 51})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	result, err := store.CaptureHold(context.Background(), CaptureHoldParams{ID: hold.ID, Amount: 30})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.Equal(t, int64(30), result.Hold.CapturedAmount)
+	require.Equal(t, result.Transaction.Tranfer.ID, result.Hold.TransferID.Int64)
+
+	// the part left uncaptured is released
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-30, updatedAccount1.Balance)
+	require.Equal(t, account1.AvailableBalance-30, updatedAccount1.AvailableBalance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+30, updatedAccount2.Balance)
+	require.Equal(t, account2.AvailableBalance+30, updatedAccount2.AvailableBalance)
+
+	_, err = store.CaptureHold(context.Background(), CaptureHoldParams{ID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestVoidHold(t *testing.T) {
+	store := NewStore(testDB)
+	hold, account1, _ := authorizeRandomHold(t, store, 50, time.Now().Add(time.Hour))
+
+	voided, err := store.VoidHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, voided.Status)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Equal(t, account1.AvailableBalance, updatedAccount1.AvailableBalance)
+
+	_, err = store.VoidHold(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestHoldReservesFunds(t *testing.T) {
+	store := NewStore(testDB)
+	hold, account1, account2 := authorizeRandomHold(t, store, 100, time.Now().Add(time.Hour))
+	account1 = setAccountBalance(t, store, account1, 150)
+
+	// the ledger balance would allow it, the available balance doesn't
+	_, err := store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.AuthorizeHold(context.Background(), AuthorizeHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      100,
+		ExpiresAt:   hold.ExpiresAt,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+}
+
+func TestReleaseExpiredHolds(t *testing.T) {
+	store := NewStore(testDB)
+	now := time.Now()
+	expired, account1, _ := authorizeRandomHold(t, store, 50, now.Add(-time.Minute))
+	active, _, _ := authorizeRandomHold(t, store, 50, now.Add(time.Hour))
+
+	_, err := store.CaptureHold(context.Background(), CaptureHoldParams{ID: expired.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	released, err := store.ReleaseExpiredHolds(context.Background(), ReleaseExpiredHoldsParams{
+		Now:       now,
+		BatchSize: 1000,
+	})
+	require.NoError(t, err)
+
+	ids := make(map[int64]Hold, len(released))
+	for _, hold := range released {
+		ids[hold.ID] = hold
+	}
+	require.Contains(t, ids, expired.ID)
+	require.Equal(t, HoldExpired, ids[expired.ID].Status)
+	require.NotContains(t, ids, active.ID)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.AvailableBalance, updatedAccount1.AvailableBalance)
+}
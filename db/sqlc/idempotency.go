@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// ErrIdempotencyKeyReused is returned when an idempotency key comes back with
+// a request different from the one it was first used with.
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used with a different request")
+
+// errIdempotencyKeyTaken rolls back a transfer when a concurrent request with
+// the same key committed first.
+var errIdempotencyKeyTaken = errors.New("idempotency key taken")
+
+type IdempotentTransactionParams struct {
+	TransactionParams
+	Username    string `json:"username"`
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"`
+}
+
+type IdempotentTransactionResult struct {
+	Transaction TransactionResult `json:"transaction"`
+	Replayed    bool              `json:"replayed"`
+}
+
+// IdempotentTransaction runs the transfer once per user and key. The key and
+// the serialized result are stored in the same transaction as the transfer,
+// so a retry either replays the stored result or runs the transfer again if
+// the first attempt never committed.
+func (store *SQLStore) IdempotentTransaction(ctx context.Context, arg IdempotentTransactionParams) (IdempotentTransactionResult, error) {
+	result, err := store.replayTransaction(ctx, arg)
+	if !errors.Is(err, ErrRecordNotFound) {
+		return result, err
+	}
+
+	err = store.execTx(ctx, store.transferIsolation, func(q *Queries) error {
+		var err error
+		result.Transaction, err = transfer(ctx, q, arg.TransactionParams)
+		if err != nil {
+			return err
+		}
+
+		response, err := json.Marshal(result.Transaction)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Username:    arg.Username,
+			Key:         arg.Key,
+			RequestHash: arg.RequestHash,
+			Response:    response,
+		})
+		if err == sql.ErrNoRows {
+			return errIdempotencyKeyTaken
+		}
+		return err
+	})
+
+	if err == errIdempotencyKeyTaken {
+		return store.replayTransaction(ctx, arg)
+	}
+	if err == nil {
+		countTransfer(transferKindTransfer, result.Transaction)
+	}
+
+	return result, err
+}
+
+// replayTransaction returns the stored result of a transfer already run with
+// the key, or ErrRecordNotFound when the key hasn't been used.
+func (store *SQLStore) replayTransaction(ctx context.Context, arg IdempotentTransactionParams) (IdempotentTransactionResult, error) {
+	var result IdempotentTransactionResult
+
+	key, err := store.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: arg.Username,
+		Key:      arg.Key,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if key.RequestHash != arg.RequestHash {
+		return result, ErrIdempotencyKeyReused
+	}
+
+	err = json.Unmarshal(key.Response, &result.Transaction)
+	result.Replayed = true
+	return result, err
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github/leoflalv/bank-api/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotentTransaction(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, randomCurrency(t))
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	arg := IdempotentTransactionParams{
+		TransactionParams: TransactionParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		},
+		Username:    account1.Owner,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(32),
+	}
+
+	first, err := store.IdempotentTransaction(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, first.Replayed)
+	require.NotZero(t, first.Transaction.Tranfer.ID)
+
+	second, err := store.IdempotentTransaction(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, second.Replayed)
+	require.Equal(t, first.Transaction.Tranfer.ID, second.Transaction.Tranfer.ID)
+	require.Equal(t, first.Transaction.FromEntry.ID, second.Transaction.FromEntry.ID)
+
+	// the retry must not move money a second time
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updatedAccount1.Balance)
+
+	arg.RequestHash = util.RandomString(32)
+	_, err = store.IdempotentTransaction(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	txCommitted    = "commit"
+	txRolledBack   = "rollback"
+	txCommitFailed = "commit_failed"
+)
+
+var (
+	txDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_transaction_duration_seconds",
+		Help:    "Time from the start of a transaction to its commit or rollback, per attempt.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"isolation", "outcome"})
+	txRollbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "db_transaction_rollbacks_total",
+		Help: "Transactions rolled back because the operation failed, retries included.",
+	})
+	transfersCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transfers_created_total",
+		Help: "Transfers committed, by what created them and the source currency.",
+	}, []string{"kind", "currency"})
+	transferredAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transferred_amount_total",
+		Help: "Amount moved by the transfers committed, in minor units of the source currency.",
+	}, []string{"kind", "currency"})
+)
+
+// What created a transfer, to tell them apart in the metrics.
+const (
+	transferKindTransfer = "transfer"
+	transferKindReversal = "reversal"
+	transferKindCapture  = "capture"
+)
+
+func observeTx(isolation sql.IsolationLevel, outcome string, start time.Time) {
+	txDuration.WithLabelValues(isolation.String(), outcome).Observe(time.Since(start).Seconds())
+}
+
+// countTransfer records a committed transfer in the metrics.
+func countTransfer(kind string, result TransactionResult) {
+	currency := result.FromAccount.Currency
+	transfersCreated.WithLabelValues(kind, currency).Inc()
+	transferredAmount.WithLabelValues(kind, currency).Add(float64(result.Tranfer.Amount))
+}
+
+// RegisterMetrics exposes the connection pool and retry stats of store in
+// the default Prometheus registry. It is called once, with the store of the
+// server.
+func RegisterMetrics(store Store) {
+	pool := func(stat func(sql.DBStats) float64) func() float64 {
+		return func() float64 { return stat(store.PoolStats()) }
+	}
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "db_pool_max_open_connections",
+		Help: "Maximum number of open connections to the database.",
+	}, pool(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "db_pool_open_connections",
+		Help: "Established connections, in use or idle.",
+	}, pool(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "db_pool_in_use_connections",
+		Help: "Connections currently in use.",
+	}, pool(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "db_pool_idle_connections",
+		Help: "Idle connections.",
+	}, pool(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_pool_wait_count_total",
+		Help: "Connections waited for.",
+	}, pool(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_pool_wait_duration_seconds_total",
+		Help: "Time blocked waiting for a connection.",
+	}, pool(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_pool_max_idle_closed_total",
+		Help: "Connections closed because of the idle limit.",
+	}, pool(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_pool_max_idle_time_closed_total",
+		Help: "Connections closed because of the idle time limit.",
+	}, pool(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_pool_max_lifetime_closed_total",
+		Help: "Connections closed because of the lifetime limit.",
+	}, pool(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_transaction_serialization_retries_total",
+		Help: "Transactions run again after a serialization failure.",
+	}, func() float64 { return float64(store.RetryStats().SerializationFailures) })
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_transaction_deadlock_retries_total",
+		Help: "Transactions run again after a deadlock.",
+	}, func() float64 { return float64(store.RetryStats().Deadlocks) })
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_transaction_retries_exhausted_total",
+		Help: "Transactions still failing after the last retry.",
+	}, func() float64 { return float64(store.RetryStats().Exhausted) })
+}
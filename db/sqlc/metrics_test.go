@@ -0,0 +1,35 @@
+package db
+
+import (
+	"database/sql"
+	"github/leoflalv/bank-api/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	RegisterMetrics(NewStore(testDB))
+
+	currency := util.USD
+	countTransfer(transferKindReversal, TransactionResult{
+		Tranfer:     Transfer{Amount: 250},
+		FromAccount: Account{Currency: currency},
+	})
+	observeTx(sql.LevelSerializable, txRolledBack, time.Now())
+
+	recorder := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	out := recorder.Body.String()
+	require.Contains(t, out, `transfers_created_total{currency="`+currency+`",kind="reversal"} `)
+	require.Contains(t, out, `transferred_amount_total{currency="`+currency+`",kind="reversal"} `)
+	require.Contains(t, out, `db_transaction_duration_seconds_count{isolation="Serializable",outcome="rollback"} `)
+	require.Contains(t, out, "\ndb_pool_open_connections ")
+	require.Contains(t, out, "\ndb_transaction_retries_exhausted_total ")
+}
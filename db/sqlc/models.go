@@ -5,7 +5,11 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -14,6 +18,34 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance can go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// balance minus the amounts reserved by active holds
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+type Adjustment struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	EntryID   int64 `json:"entry_id"`
+	// can be negative or positive, never zero
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+	// external ticket or document backing the adjustment
+	Reference sql.NullString `json:"reference"`
+	CreatedBy string         `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code string `json:"code"`
+	// ISO 4217 numeric code
+	NumericCode string `json:"numeric_code"`
+	// number of minor unit digits, amounts are stored in minor units
+	Exponent  int16     `json:"exponent"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -22,6 +54,109 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer the entry is a side of, null for adjustments
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type Hold struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// account credited when the hold is captured
+	ToAccountID    int64 `json:"to_account_id"`
+	Amount         int64 `json:"amount"`
+	CapturedAmount int64 `json:"captured_amount"`
+	// active, captured, voided or expired
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// sha256 of the request body the key was first used with
+	RequestHash string `json:"request_hash"`
+	// serialized result replayed for retries of the same request
+	Response  json.RawMessage `json:"response"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type Payment struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// deposit or withdrawal
+	Kind     string `json:"kind"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// pending, completed or failed
+	Status string `json:"status"`
+	// id of the payment on the payment rail
+	RailReference sql.NullString `json:"rail_reference"`
+	FailureReason sql.NullString `json:"failure_reason"`
+	// transfer between the account and the settlement account
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// transfer giving the funds back when a withdrawal fails
+	RefundTransferID sql.NullInt64 `json:"refund_transfer_id"`
+	CreatedBy        string        `json:"created_by"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+type ReversalConsent struct {
+	TransferID int64 `json:"transfer_id"`
+	// owner of the account credited by the transfer
+	GrantedBy string    `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RevokedToken struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// cron expression or descriptor such as @every 24h
+	Schedule  string    `json:"schedule"`
+	NextRunAt time.Time `json:"next_run_at"`
+	// no run happens after it
+	EndAt sql.NullTime `json:"end_at"`
+	// active, paused, completed or cancelled
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64 `json:"id"`
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// the occurrence of the schedule the run executed
+	ScheduledFor time.Time `json:"scheduled_for"`
+	// succeeded or failed
+	Status     string         `json:"status"`
+	TransferID sql.NullInt64  `json:"transfer_id"`
+	Error      sql.NullString `json:"error"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type Session struct {
+	ID               uuid.UUID `json:"id"`
+	Username         string    `json:"username"`
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	UserAgent        string    `json:"user_agent"`
+	ClientIp         string    `json:"client_ip"`
+	IsBlocked        bool      `json:"is_blocked"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	CreatedAt        time.Time `json:"created_at"`
 }
 
 type Transfer struct {
@@ -31,6 +166,14 @@ type Transfer struct {
 	// it must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// amount credited in the destination account currency
+	ToAmount int64 `json:"to_amount"`
+	// destination currency units per source currency unit
+	ExchangeRate float64 `json:"exchange_rate"`
+	// when the exchange rate was quoted, null for same currency transfers
+	RateAt sql.NullTime `json:"rate_at"`
+	// transfer this one reverses, null for regular transfers
+	ReversalOf sql.NullInt64 `json:"reversal_of"`
 }
 
 type User struct {
@@ -40,4 +183,7 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// tokens issued before this time are revoked
+	TokensValidAfter time.Time `json:"tokens_valid_after"`
+	Role             string    `json:"role"`
 }
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"math"
+)
+
+const (
+	SortByCreatedAt = "created_at"
+	SortByAmount    = "amount"
+)
+
+// PageCursor is the position of the last row of a page in the order the page
+// was sorted by. The next page starts right after it.
+type PageCursor struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount,omitempty"`
+}
+
+// HistoryPageParams filters and sorts the entries or transfers of an account.
+// A nil After returns the first page.
+type HistoryPageParams struct {
+	AccountID int64          `json:"account_id"`
+	FromTime  sql.NullTime   `json:"from_time"`
+	ToTime    sql.NullTime   `json:"to_time"`
+	Direction sql.NullString `json:"direction"`
+	MinAmount sql.NullInt64  `json:"min_amount"`
+	MaxAmount sql.NullInt64  `json:"max_amount"`
+	SortBy    string         `json:"sort_by"`
+	SortDesc  bool           `json:"sort_desc"`
+	After     *PageCursor    `json:"after"`
+	Limit     int32          `json:"limit"`
+}
+
+// cursor returns where the page starts. Without a cursor it's a position
+// before every row in the requested order, so the first page can use the same
+// index seek as the following ones.
+func (arg HistoryPageParams) cursor() PageCursor {
+	if arg.After != nil {
+		return *arg.After
+	}
+
+	if arg.SortDesc {
+		return PageCursor{ID: math.MaxInt64, Amount: math.MaxInt64}
+	}
+
+	return PageCursor{ID: 0, Amount: -1}
+}
+
+// ListAccountEntriesPage returns a page of the entries of an account using the
+// keyset query matching the requested order.
+func (q *Queries) ListAccountEntriesPage(ctx context.Context, arg HistoryPageParams) ([]Entry, error) {
+	cursor := arg.cursor()
+
+	if arg.SortBy == SortByAmount {
+		byAmount := ListAccountEntriesByAmountParams{
+			AccountID:    arg.AccountID,
+			FromTime:     arg.FromTime,
+			ToTime:       arg.ToTime,
+			Direction:    arg.Direction,
+			MinAmount:    arg.MinAmount,
+			MaxAmount:    arg.MaxAmount,
+			CursorAmount: cursor.Amount,
+			CursorID:     cursor.ID,
+			PageLimit:    arg.Limit,
+		}
+
+		if arg.SortDesc {
+			return q.ListAccountEntriesByAmountDesc(ctx, ListAccountEntriesByAmountDescParams(byAmount))
+		}
+		return q.ListAccountEntriesByAmount(ctx, byAmount)
+	}
+
+	byID := ListAccountEntriesByIDParams{
+		AccountID: arg.AccountID,
+		FromTime:  arg.FromTime,
+		ToTime:    arg.ToTime,
+		Direction: arg.Direction,
+		MinAmount: arg.MinAmount,
+		MaxAmount: arg.MaxAmount,
+		CursorID:  cursor.ID,
+		PageLimit: arg.Limit,
+	}
+
+	if arg.SortDesc {
+		return q.ListAccountEntriesByIDDesc(ctx, ListAccountEntriesByIDDescParams(byID))
+	}
+	return q.ListAccountEntriesByID(ctx, byID)
+}
+
+// ListAccountTransfersPage returns a page of the transfers sent or received by
+// an account using the keyset query matching the requested order. Each query
+// seeks the sent and received transfers on their own index and merges them.
+func (q *Queries) ListAccountTransfersPage(ctx context.Context, arg HistoryPageParams) ([]Transfer, error) {
+	cursor := arg.cursor()
+
+	if arg.SortBy == SortByAmount {
+		byAmount := ListAccountTransfersByAmountParams{
+			AccountID:    arg.AccountID,
+			FromTime:     arg.FromTime,
+			ToTime:       arg.ToTime,
+			Direction:    arg.Direction,
+			MinAmount:    arg.MinAmount,
+			MaxAmount:    arg.MaxAmount,
+			CursorAmount: cursor.Amount,
+			CursorID:     cursor.ID,
+			PageLimit:    arg.Limit,
+		}
+
+		if arg.SortDesc {
+			return q.ListAccountTransfersByAmountDesc(ctx, ListAccountTransfersByAmountDescParams(byAmount))
+		}
+		return q.ListAccountTransfersByAmount(ctx, byAmount)
+	}
+
+	byID := ListAccountTransfersByIDParams{
+		AccountID: arg.AccountID,
+		FromTime:  arg.FromTime,
+		ToTime:    arg.ToTime,
+		Direction: arg.Direction,
+		MinAmount: arg.MinAmount,
+		MaxAmount: arg.MaxAmount,
+		CursorID:  cursor.ID,
+		PageLimit: arg.Limit,
+	}
+
+	if arg.SortDesc {
+		return q.ListAccountTransfersByIDDesc(ctx, ListAccountTransfersByIDDescParams(byID))
+	}
+	return q.ListAccountTransfersByID(ctx, byID)
+}
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SystemUsername owns the settlement accounts, one per currency, which are the
+// counterpart of every deposit and withdrawal. Usernames are alphanumeric, so
+// no user can take it.
+const SystemUsername = "_system"
+
+const (
+	PaymentDeposit    = "deposit"
+	PaymentWithdrawal = "withdrawal"
+)
+
+const (
+	PaymentPending   = "pending"
+	PaymentCompleted = "completed"
+	PaymentFailed    = "failed"
+)
+
+// ErrPaymentNotPending is returned when settling a payment whose outcome is
+// already known.
+var ErrPaymentNotPending = errors.New("payment is not pending")
+
+type StartPaymentParams struct {
+	Kind      string `json:"kind"`
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	CreatedBy string `json:"created_by"`
+}
+
+// StartPayment records a pending deposit or withdrawal. A withdrawal takes the
+// funds out of the account right away so they can't be spent while the rail
+// processes it, a deposit only credits the account once completed.
+func (store *SQLStore) StartPayment(ctx context.Context, arg StartPaymentParams) (Payment, error) {
+	var payment Payment
+
+	if arg.Kind != PaymentDeposit && arg.Kind != PaymentWithdrawal {
+		return payment, fmt.Errorf("unknown payment kind %q", arg.Kind)
+	}
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return accountError(err)
+		}
+
+		var transferID sql.NullInt64
+		if arg.Kind == PaymentWithdrawal {
+			result, err := settlementTransfer(ctx, q, account, arg.Amount, false)
+			if err != nil {
+				return err
+			}
+			transferID = sql.NullInt64{Int64: result.Tranfer.ID, Valid: true}
+		}
+
+		payment, err = q.CreatePayment(ctx, CreatePaymentParams{
+			AccountID:  account.ID,
+			Kind:       arg.Kind,
+			Amount:     arg.Amount,
+			Currency:   account.Currency,
+			TransferID: transferID,
+			CreatedBy:  arg.CreatedBy,
+		})
+		return err
+	})
+
+	return payment, err
+}
+
+type SettlePaymentParams struct {
+	ID            int64          `json:"id"`
+	Status        string         `json:"status"`
+	RailReference sql.NullString `json:"rail_reference"`
+	FailureReason sql.NullString `json:"failure_reason"`
+}
+
+// SettlePayment records the outcome reported by the payment rail. A completed
+// deposit credits the account and a failed withdrawal gives the funds back,
+// while a pending status only records the rail reference.
+func (store *SQLStore) SettlePayment(ctx context.Context, arg SettlePaymentParams) (Payment, error) {
+	var payment Payment
+
+	switch arg.Status {
+	case PaymentPending, PaymentCompleted, PaymentFailed:
+	default:
+		return payment, fmt.Errorf("unknown payment status %q", arg.Status)
+	}
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		var err error
+		payment, err = q.GetPaymentForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if payment.Status != PaymentPending {
+			return ErrPaymentNotPending
+		}
+
+		update := UpdatePaymentParams{
+			ID:               payment.ID,
+			Status:           arg.Status,
+			RailReference:    arg.RailReference,
+			FailureReason:    arg.FailureReason,
+			TransferID:       payment.TransferID,
+			RefundTransferID: payment.RefundTransferID,
+		}
+
+		deposited := payment.Kind == PaymentDeposit && arg.Status == PaymentCompleted
+		refunded := payment.Kind == PaymentWithdrawal && arg.Status == PaymentFailed
+
+		if deposited || refunded {
+			account, err := q.GetAccount(ctx, payment.AccountID)
+			if err != nil {
+				return err
+			}
+
+			result, err := settlementTransfer(ctx, q, account, payment.Amount, true)
+			if err != nil {
+				return err
+			}
+
+			transferID := sql.NullInt64{Int64: result.Tranfer.ID, Valid: true}
+			if deposited {
+				update.TransferID = transferID
+			} else {
+				update.RefundTransferID = transferID
+			}
+		}
+
+		payment, err = q.UpdatePayment(ctx, update)
+		return err
+	})
+
+	return payment, err
+}
+
+// settlementTransfer moves amount between the account and the settlement
+// account of its currency, into the account when credit is set and out of it
+// otherwise. The settlement account mirrors money held outside the bank, so
+// only the customer account is checked for funds.
+func settlementTransfer(ctx context.Context, q *Queries, account Account, amount int64, credit bool) (TransactionResult, error) {
+	settlement, err := settlementAccount(ctx, q, account.Currency)
+	if err != nil {
+		return TransactionResult{}, err
+	}
+
+	fromAccountID, toAccountID := account.ID, settlement.ID
+	if credit {
+		fromAccountID, toAccountID = settlement.ID, account.ID
+	}
+
+	fromAccount, _, err := lockAccounts(ctx, q, fromAccountID, toAccountID)
+	if err != nil {
+		return TransactionResult{}, err
+	}
+
+	if !credit && fromAccount.AvailableBalance-amount < -fromAccount.OverdraftLimit {
+		return TransactionResult{}, ErrInsufficientFunds
+	}
+
+	return recordTransfer(ctx, q, CreateTransferParams{
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  1,
+	})
+}
+
+// settlementAccount returns the settlement account of the currency, opening it
+// the first time the currency is used.
+func settlementAccount(ctx context.Context, q *Queries, currency string) (Account, error) {
+	account, err := q.GetSettlementAccount(ctx, currency)
+	if err != sql.ErrNoRows {
+		return account, err
+	}
+
+	err = q.CreateSettlementAccount(ctx, currency)
+	if err != nil {
+		return account, err
+	}
+
+	return q.GetSettlementAccount(ctx, currency)
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositPayment(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	settlement, err := settlementAccount(context.Background(), testQueries, account.Currency)
+	require.NoError(t, err)
+	require.Equal(t, SystemUsername, settlement.Owner)
+
+	payment, err := store.StartPayment(context.Background(), StartPaymentParams{
+		Kind:      PaymentDeposit,
+		AccountID: account.ID,
+		Amount:    100,
+		CreatedBy: account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentPending, payment.Status)
+	require.Equal(t, account.Currency, payment.Currency)
+	require.False(t, payment.TransferID.Valid)
+
+	// nothing moves until the rail completes the deposit
+	pendingAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, pendingAccount.Balance)
+
+	payment, err = store.SettlePayment(context.Background(), SettlePaymentParams{
+		ID:            payment.ID,
+		Status:        PaymentCompleted,
+		RailReference: sql.NullString{String: "rail-1", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentCompleted, payment.Status)
+	require.Equal(t, "rail-1", payment.RailReference.String)
+	require.True(t, payment.TransferID.Valid)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), payment.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, settlement.ID, transfer.FromAccountID)
+	require.Equal(t, account.ID, transfer.ToAccountID)
+	require.Equal(t, payment.Amount, transfer.Amount)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+payment.Amount, updatedAccount.Balance)
+
+	_, err = store.SettlePayment(context.Background(), SettlePaymentParams{
+		ID:     payment.ID,
+		Status: PaymentCompleted,
+	})
+	require.ErrorIs(t, err, ErrPaymentNotPending)
+}
+
+func TestWithdrawalPayment(t *testing.T) {
+	store := NewStore(testDB)
+	account := createFundedAccount(t, randomCurrency(t))
+
+	payment, err := store.StartPayment(context.Background(), StartPaymentParams{
+		Kind:      PaymentWithdrawal,
+		AccountID: account.ID,
+		Amount:    account.Balance,
+		CreatedBy: account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentPending, payment.Status)
+	require.True(t, payment.TransferID.Valid)
+
+	// the funds are taken while the rail processes the withdrawal
+	pendingAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), pendingAccount.Balance)
+
+	_, err = store.StartPayment(context.Background(), StartPaymentParams{
+		Kind:      PaymentWithdrawal,
+		AccountID: account.ID,
+		Amount:    1,
+		CreatedBy: account.Owner,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	payment, err = store.SettlePayment(context.Background(), SettlePaymentParams{
+		ID:            payment.ID,
+		Status:        PaymentFailed,
+		FailureReason: sql.NullString{String: "account closed", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentFailed, payment.Status)
+	require.Equal(t, "account closed", payment.FailureReason.String)
+	require.True(t, payment.RefundTransferID.Valid)
+
+	refundedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, refundedAccount.Balance)
+}
+
+func TestListPendingPayments(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	payment, err := store.StartPayment(context.Background(), StartPaymentParams{
+		Kind:      PaymentDeposit,
+		AccountID: account.ID,
+		Amount:    100,
+		CreatedBy: account.Owner,
+	})
+	require.NoError(t, err)
+
+	arg := ListPendingPaymentsParams{
+		CreatedBefore: payment.CreatedAt,
+		AfterID:       payment.ID - 1,
+		BatchSize:     1,
+	}
+
+	pending, err := testQueries.ListPendingPayments(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, payment.ID, pending[0].ID)
+
+	// payments made after CreatedBefore are left to the request making them
+	pending, err = testQueries.ListPendingPayments(context.Background(), ListPendingPaymentsParams{
+		CreatedBefore: payment.CreatedAt.Add(-time.Second),
+		AfterID:       payment.ID - 1,
+		BatchSize:     1,
+	})
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	_, err = store.SettlePayment(context.Background(), SettlePaymentParams{
+		ID:     payment.ID,
+		Status: PaymentFailed,
+	})
+	require.NoError(t, err)
+
+	pending, err = testQueries.ListPendingPayments(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
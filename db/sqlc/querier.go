@@ -6,24 +6,80 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AccountHasActivity(ctx context.Context, id int64) (bool, error)
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockSession(ctx context.Context, arg BlockSessionParams) error
+	BlockUserSessions(ctx context.Context, username string) error
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
+	CreateReversalConsent(ctx context.Context, arg CreateReversalConsentParams) (ReversalConsent, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSettlementAccount(ctx context.Context, currency string) error
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccount(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAdjustment(ctx context.Context, id int64) (Adjustment, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetPayment(ctx context.Context, id int64) (Payment, error)
+	GetPaymentForUpdate(ctx context.Context, id int64) (Payment, error)
+	GetReversalConsent(ctx context.Context, transferID int64) (ReversalConsent, error)
+	GetReversedAmounts(ctx context.Context, reversalOf sql.NullInt64) (GetReversedAmountsRow, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSettlementAccount(ctx context.Context, currency string) (Account, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	IsTokenRevoked(ctx context.Context, arg IsTokenRevokedParams) (bool, error)
+	IsTransferLinked(ctx context.Context, id int64) (bool, error)
+	ListAccountEntriesByAmount(ctx context.Context, arg ListAccountEntriesByAmountParams) ([]Entry, error)
+	ListAccountEntriesByAmountDesc(ctx context.Context, arg ListAccountEntriesByAmountDescParams) ([]Entry, error)
+	ListAccountEntriesByID(ctx context.Context, arg ListAccountEntriesByIDParams) ([]Entry, error)
+	ListAccountEntriesByIDDesc(ctx context.Context, arg ListAccountEntriesByIDDescParams) ([]Entry, error)
+	ListAccountTransfersByAmount(ctx context.Context, arg ListAccountTransfersByAmountParams) ([]Transfer, error)
+	ListAccountTransfersByAmountDesc(ctx context.Context, arg ListAccountTransfersByAmountDescParams) ([]Transfer, error)
+	ListAccountTransfersByID(ctx context.Context, arg ListAccountTransfersByIDParams) ([]Transfer, error)
+	ListAccountTransfersByIDDesc(ctx context.Context, arg ListAccountTransfersByIDDescParams) ([]Transfer, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAdjustments(ctx context.Context, arg ListAdjustmentsParams) ([]Adjustment, error)
+	ListBalanceDiscrepancies(ctx context.Context) ([]ListBalanceDiscrepanciesRow, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListDueScheduledTransfersForUpdate(ctx context.Context, arg ListDueScheduledTransfersForUpdateParams) ([]ScheduledTransfer, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExpiredHoldsForUpdate(ctx context.Context, arg ListExpiredHoldsForUpdateParams) ([]Hold, error)
+	ListPendingPayments(ctx context.Context, arg ListPendingPaymentsParams) ([]Payment, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
-	UpdateAccounts(ctx context.Context, arg UpdateAccountsParams) (Account, error)
+	ListUnmatchedTransfers(ctx context.Context) ([]ListUnmatchedTransfersRow, error)
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error
+	SumAccountEntriesSince(ctx context.Context, arg SumAccountEntriesSinceParams) (int64, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdatePayment(ctx context.Context, arg UpdatePaymentParams) (Payment, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
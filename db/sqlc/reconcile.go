@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Reconciliation lists where the ledger disagrees with itself: accounts whose
+// balance isn't the sum of their entries, and transfers without exactly one
+// debit entry on the source account and one credit entry on the destination.
+type Reconciliation struct {
+	Balances  []ListBalanceDiscrepanciesRow `json:"balances"`
+	Transfers []ListUnmatchedTransfersRow   `json:"transfers"`
+}
+
+// Balanced reports whether no discrepancy was found.
+func (r Reconciliation) Balanced() bool {
+	return len(r.Balances) == 0 && len(r.Transfers) == 0
+}
+
+// Reconcile checks the balances and transfers against the entries. Both
+// checks read the same snapshot, so transfers committing meanwhile don't
+// show up as discrepancies.
+func (store *SQLStore) Reconcile(ctx context.Context) (Reconciliation, error) {
+	var result Reconciliation
+
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return result, err
+	}
+	// The transaction is read only, there's nothing to commit.
+	defer tx.Rollback()
+
+	q := New(tracedDBTX{db: tx})
+	result.Balances, err = q.ListBalanceDiscrepancies(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfers, err = q.ListUnmatchedTransfers(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	store := NewStore(testDB)
+
+	// Random accounts start with a balance but no entries.
+	drifted := createRandomAccount(t)
+
+	balanced, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    drifted.Owner,
+		Balance:  0,
+		Currency: drifted.Currency,
+	})
+	require.NoError(t, err)
+	balanced = setAccountBalance(t, store, balanced, 500)
+
+	unmatched := createRandomTransfer(balanced, drifted)
+
+	// only the debit of this one is linked to it, an entry of the same
+	// account and amount that isn't linked doesn't count
+	halfMatched := createRandomTransfer(createRandomAccount(t), createRandomAccount(t))
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:  halfMatched.FromAccountID,
+		Amount:     -halfMatched.Amount,
+		TransferID: sql.NullInt64{Int64: halfMatched.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: halfMatched.ToAccountID,
+		Amount:    halfMatched.ToAmount,
+	})
+	require.NoError(t, err)
+
+	matched, err := store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: balanced.ID,
+		ToAccountID:   drifted.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, matched.Tranfer.ID, matched.FromEntry.TransferID.Int64)
+	require.Equal(t, matched.Tranfer.ID, matched.ToEntry.TransferID.Int64)
+
+	result, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.False(t, result.Balanced())
+
+	balances := map[int64]ListBalanceDiscrepanciesRow{}
+	for _, row := range result.Balances {
+		balances[row.AccountID] = row
+	}
+	require.Contains(t, balances, drifted.ID)
+	require.Equal(t, drifted.Balance+matched.ToEntry.Amount, balances[drifted.ID].Balance)
+	require.Equal(t, matched.ToEntry.Amount, balances[drifted.ID].EntriesTotal)
+	require.NotContains(t, balances, balanced.ID)
+
+	transfers := map[int64]ListUnmatchedTransfersRow{}
+	for _, row := range result.Transfers {
+		transfers[row.ID] = row
+	}
+	require.Contains(t, transfers, unmatched.ID)
+	require.Zero(t, transfers[unmatched.ID].DebitEntries)
+	require.Zero(t, transfers[unmatched.ID].CreditEntries)
+	require.Contains(t, transfers, halfMatched.ID)
+	require.Equal(t, int64(1), transfers[halfMatched.ID].DebitEntries)
+	require.Zero(t, transfers[halfMatched.ID].CreditEntries)
+	require.NotContains(t, transfers, matched.Tranfer.ID)
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+)
+
+// ErrReversalExceedsTransfer is returned when a reversal would give back more
+// than what is left to reverse of the original transfer.
+var ErrReversalExceedsTransfer = errors.New("reversal exceeds the amount left to reverse")
+
+// ErrReversalOfReversal is returned when the transfer to reverse is itself a
+// reversal.
+var ErrReversalOfReversal = errors.New("a reversal can't be reversed")
+
+// ErrReversalOfLinkedTransfer is returned when the transfer to reverse moved
+// the funds of a payment or a hold, which have to be refunded or voided
+// through their own endpoints to stay consistent.
+var ErrReversalOfLinkedTransfer = errors.New("the transfer of a payment or a hold can't be reversed")
+
+// ReverseTransferParams gives Amount back to the source account of the
+// transfer, in the currency the transfer was sent in. A zero Amount reverses
+// everything left.
+type ReverseTransferParams struct {
+	TransferID int64 `json:"transfer_id"`
+	Amount     int64 `json:"amount"`
+}
+
+// ReverseTransfer records a compensating transfer from the destination
+// account of the original transfer back to its source account. The reversals
+// of a transfer can't add up to more than it.
+func (store *SQLStore) ReverseTransfer(ctx context.Context, arg ReverseTransferParams) (TransactionResult, error) {
+	var result TransactionResult
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		// Locking the original transfer serializes its reversals.
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOf.Valid {
+			return ErrReversalOfReversal
+		}
+
+		linked, err := q.IsTransferLinked(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+		if linked {
+			return ErrReversalOfLinkedTransfer
+		}
+
+		reversed, err := q.GetReversedAmounts(ctx, sql.NullInt64{Int64: original.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+
+		// Reversals run in the opposite direction, what they credit is in
+		// the currency of the original amount.
+		remaining := original.Amount - reversed.ToAmount
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount <= 0 || amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		// The recipient gives back its share at the original rate so nobody
+		// gains or loses on the exchange, the last reversal takes whatever
+		// rounding left.
+		debit := original.ToAmount - reversed.Amount
+		if amount < remaining {
+			share := new(big.Int).Mul(big.NewInt(amount), big.NewInt(original.ToAmount))
+			debit = roundRat(new(big.Rat).SetFrac(share, big.NewInt(original.Amount))).Int64()
+		}
+		if debit < 1 {
+			return ErrAmountTooSmall
+		}
+
+		fromAccount, _, err := lockAccounts(ctx, q, original.ToAccountID, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		if fromAccount.AvailableBalance-debit < -fromAccount.OverdraftLimit {
+			return ErrInsufficientFunds
+		}
+
+		transferArg := CreateTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        debit,
+			ToAmount:      amount,
+			ExchangeRate:  1,
+			ReversalOf:    sql.NullInt64{Int64: original.ID, Valid: true},
+		}
+		if original.RateAt.Valid {
+			transferArg.ExchangeRate = 1 / original.ExchangeRate
+			transferArg.RateAt = original.RateAt
+		}
+
+		result, err = recordTransfer(ctx, q, transferArg)
+		return err
+	})
+	if err == nil {
+		countTransfer(transferKindReversal, result)
+	}
+
+	return result, err
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransfer(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, randomCurrency(t))
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	original, err := store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	partial, err := store.ReverseTransfer(context.Background(), ReverseTransferParams{
+		TransferID: original.Tranfer.ID,
+		Amount:     4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account2.ID, partial.Tranfer.FromAccountID)
+	require.Equal(t, account1.ID, partial.Tranfer.ToAccountID)
+	require.Equal(t, int64(4), partial.Tranfer.Amount)
+	require.Equal(t, sql.NullInt64{Int64: original.Tranfer.ID, Valid: true}, partial.Tranfer.ReversalOf)
+	require.Equal(t, int64(-4), partial.FromEntry.Amount)
+	require.Equal(t, int64(4), partial.ToEntry.Amount)
+
+	_, err = store.ReverseTransfer(context.Background(), ReverseTransferParams{
+		TransferID: original.Tranfer.ID,
+		Amount:     7,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// without an amount, whatever is left is reversed
+	rest, err := store.ReverseTransfer(context.Background(), ReverseTransferParams{
+		TransferID: original.Tranfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), rest.Tranfer.Amount)
+
+	_, err = store.ReverseTransfer(context.Background(), ReverseTransferParams{
+		TransferID: original.Tranfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	_, err = store.ReverseTransfer(context.Background(), ReverseTransferParams{
+		TransferID: rest.Tranfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReversalOfReversal)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestReverseTransferConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, randomCurrency(t))
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	original, err := store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransfer(context.Background(), ReverseTransferParams{
+				TransferID: original.Tranfer.ID,
+				Amount:     3,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+	}
+	require.Equal(t, 3, succeeded)
+
+	reversed, err := testQueries.GetReversedAmounts(context.Background(), sql.NullInt64{Int64: original.Tranfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(9), reversed.ToAmount)
+}
+
+func TestReverseLinkedTransfer(t *testing.T) {
+	store := NewStore(testDB)
+
+	// a deposit is refunded through the payment rail, not reversed
+	account := createRandomAccount(t)
+	payment, err := store.StartPayment(context.Background(), StartPaymentParams{
+		Kind:      PaymentDeposit,
+		AccountID: account.ID,
+		Amount:    100,
+		CreatedBy: account.Owner,
+	})
+	require.NoError(t, err)
+
+	payment, err = store.SettlePayment(context.Background(), SettlePaymentParams{
+		ID:     payment.ID,
+		Status: PaymentCompleted,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransfer(context.Background(), ReverseTransferParams{
+		TransferID: payment.TransferID.Int64,
+	})
+	require.ErrorIs(t, err, ErrReversalOfLinkedTransfer)
+
+	// nor is the capture of a hold
+	hold, _, _ := authorizeRandomHold(t, store, 50, time.Now().Add(time.Hour))
+	captured, err := store.CaptureHold(context.Background(), CaptureHoldParams{ID: hold.ID})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransfer(context.Background(), ReverseTransferParams{
+		TransferID: captured.Hold.TransferID.Int64,
+	})
+	require.ErrorIs(t, err, ErrReversalOfLinkedTransfer)
+
+	updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+payment.Amount, updatedAccount.Balance)
+}
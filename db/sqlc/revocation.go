@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type RevokeAllTokensParams struct {
+	Username   string    `json:"username"`
+	ValidAfter time.Time `json:"valid_after"`
+}
+
+// RevokeAllTokens invalidates every token issued to the user before
+// ValidAfter and blocks all of its sessions so they can't be renewed.
+func (store *SQLStore) RevokeAllTokens(ctx context.Context, arg RevokeAllTokensParams) error {
+	return store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		return revokeAllTokens(ctx, q, arg)
+	})
+}
+
+type ChangeUserRoleParams struct {
+	Username   string    `json:"username"`
+	Role       string    `json:"role"`
+	ValidAfter time.Time `json:"valid_after"`
+}
+
+// ChangeUserRole updates the role of the user and revokes the tokens issued
+// before ValidAfter in the same transaction. Tokens carry the role, none
+// issued with the old one stays valid once the new one is stored.
+func (store *SQLStore) ChangeUserRole(ctx context.Context, arg ChangeUserRoleParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		var err error
+		user, err = q.UpdateUserRole(ctx, UpdateUserRoleParams{
+			Username: arg.Username,
+			Role:     arg.Role,
+		})
+		if err != nil {
+			return err
+		}
+
+		return revokeAllTokens(ctx, q, RevokeAllTokensParams{
+			Username:   arg.Username,
+			ValidAfter: arg.ValidAfter,
+		})
+	})
+
+	return user, err
+}
+
+func revokeAllTokens(ctx context.Context, q *Queries, arg RevokeAllTokensParams) error {
+	err := q.RevokeUserTokens(ctx, RevokeUserTokensParams{
+		ValidAfter: arg.ValidAfter,
+		Username:   arg.Username,
+	})
+	if err != nil {
+		return err
+	}
+
+	return q.BlockUserSessions(ctx, arg.Username)
+}
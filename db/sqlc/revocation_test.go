@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"github/leoflalv/bank-api/util"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeToken(t *testing.T) {
+	user := createRandomUser()
+
+	arg := IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: user.Username,
+		IssuedAt: time.Now(),
+	}
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	err = testQueries.RevokeToken(context.Background(), RevokeTokenParams{
+		ID:        arg.ID,
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestRevokeAllTokens(t *testing.T) {
+	store := NewStore(testDB)
+	session := createRandomSession(t)
+
+	issuedAt := time.Now()
+	validAfter := issuedAt.Add(time.Second)
+
+	err := store.RevokeAllTokens(context.Background(), RevokeAllTokensParams{
+		Username:   session.Username,
+		ValidAfter: validAfter,
+	})
+	require.NoError(t, err)
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: session.Username,
+		IssuedAt: issuedAt,
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: session.Username,
+		IssuedAt: validAfter.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	blockedSession, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+}
+
+func TestChangeUserRole(t *testing.T) {
+	store := NewStore(testDB)
+	session := createRandomSession(t)
+
+	issuedAt := time.Now()
+	validAfter := issuedAt.Add(time.Second)
+
+	user, err := store.ChangeUserRole(context.Background(), ChangeUserRoleParams{
+		Username:   session.Username,
+		Role:       util.BankerRole,
+		ValidAfter: validAfter,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.BankerRole, user.Role)
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: session.Username,
+		IssuedAt: issuedAt,
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	blockedSession, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+
+	_, err = store.ChangeUserRole(context.Background(), ChangeUserRoleParams{
+		Username:   session.Username + "-missing",
+		Role:       util.BankerRole,
+		ValidAfter: validAfter,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
@@ -0,0 +1,258 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github/leoflalv/bank-api/util"
+	"log/slog"
+	"time"
+)
+
+const (
+	ScheduledTransferActive    = "active"
+	ScheduledTransferPaused    = "paused"
+	ScheduledTransferCompleted = "completed"
+	ScheduledTransferCancelled = "cancelled"
+)
+
+const (
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunFailed    = "failed"
+)
+
+type RunScheduledTransfersParams struct {
+	Now       time.Time `json:"now"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+// RunScheduledTransfers executes the active scheduled transfers due at Now,
+// skipping the ones another runner holds. In a single transaction, each one
+// is locked, its transfer made, its run recorded and the row moved to its
+// next occurrence, so an occurrence can't be lost or run twice. Occurrences
+// missed while no runner was up collapse into one. A transfer that fails
+// is recorded as a failed run without stopping the others, any other error
+// rolls the whole batch back for the next call to run again.
+func (store *SQLStore) RunScheduledTransfers(ctx context.Context, arg RunScheduledTransfersParams) ([]ScheduledTransferRun, error) {
+	var runs []ScheduledTransferRun
+	var results []TransactionResult
+
+	err := store.execTx(ctx, store.transferIsolation, func(q *Queries) error {
+		runs, results = nil, nil
+
+		due, err := q.ListDueScheduledTransfersForUpdate(ctx, ListDueScheduledTransfersForUpdateParams{
+			Now:       arg.Now,
+			BatchSize: arg.BatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, scheduled := range due {
+			run, result, err := runScheduledTransfer(ctx, q, scheduled)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			if result != nil {
+				results = append(results, *result)
+			}
+
+			err = advanceScheduledTransfer(ctx, q, scheduled, arg.Now)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		countTransfer(transferKindTransfer, result)
+	}
+	return runs, nil
+}
+
+// runScheduledTransfer makes the transfer of the due occurrence of scheduled
+// and records the run. The transfer runs behind a savepoint, so that when it
+// fails only its own statements are undone; the errors that abort the whole
+// transaction to run it again are returned instead.
+func runScheduledTransfer(ctx context.Context, q *Queries, scheduled ScheduledTransfer) (ScheduledTransferRun, *TransactionResult, error) {
+	arg := CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledFor:        scheduled.NextRunAt,
+		Status:              ScheduledRunSucceeded,
+	}
+
+	_, err := q.db.ExecContext(ctx, "SAVEPOINT scheduled_transfer")
+	if err != nil {
+		return ScheduledTransferRun{}, nil, err
+	}
+
+	result, err := transfer(ctx, q, TransactionParams{
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+	})
+	switch {
+	case retryableError(err) != "":
+		return ScheduledTransferRun{}, nil, err
+	case err != nil:
+		_, rbErr := q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT scheduled_transfer")
+		if rbErr != nil {
+			return ScheduledTransferRun{}, nil, rbErr
+		}
+		slog.WarnContext(ctx, "scheduled transfer failed", "scheduled_transfer_id", scheduled.ID, "error", err)
+		arg.Status = ScheduledRunFailed
+		arg.Error = sql.NullString{String: scheduledRunError(err), Valid: true}
+	default:
+		_, err = q.db.ExecContext(ctx, "RELEASE SAVEPOINT scheduled_transfer")
+		if err != nil {
+			return ScheduledTransferRun{}, nil, err
+		}
+		arg.TransferID = sql.NullInt64{Int64: result.Tranfer.ID, Valid: true}
+	}
+
+	run, err := q.CreateScheduledTransferRun(ctx, arg)
+	if err != nil || arg.Status == ScheduledRunFailed {
+		return run, nil, err
+	}
+	return run, &result, nil
+}
+
+// scheduledRunErrors are the errors of a transfer whose message is stored as
+// the reason of a failed run, which the owner of the scheduled transfer can
+// read. The others are only logged.
+var scheduledRunErrors = []error{
+	ErrAccountNotFound,
+	ErrInsufficientFunds,
+	ErrCurrencyMismatch,
+	ErrAmountTooSmall,
+}
+
+// scheduledRunInternalError is the reason of the runs that failed on an error
+// not meant for the owner.
+const scheduledRunInternalError = "internal error"
+
+// scheduledRunError returns the reason stored for a run failed with err.
+func scheduledRunError(err error) string {
+	for _, public := range scheduledRunErrors {
+		if errors.Is(err, public) {
+			return public.Error()
+		}
+	}
+	return scheduledRunInternalError
+}
+
+// advanceScheduledTransfer moves scheduled, whose due occurrence just ran, to
+// its first occurrence after now, or completes it when that falls after its
+// end.
+func advanceScheduledTransfer(ctx context.Context, q *Queries, scheduled ScheduledTransfer, now time.Time) error {
+	update := UpdateScheduledTransferParams{
+		ID:        scheduled.ID,
+		Amount:    scheduled.Amount,
+		Schedule:  scheduled.Schedule,
+		NextRunAt: scheduled.NextRunAt,
+		EndAt:     scheduled.EndAt,
+		Status:    scheduled.Status,
+	}
+
+	next, err := util.NextScheduledTime(scheduled.Schedule, now)
+	switch {
+	case err != nil:
+		// Schedules are validated when saved, this one still got its due
+		// run but no other until it's fixed.
+		update.Status = ScheduledTransferPaused
+	case scheduled.EndAt.Valid && next.After(scheduled.EndAt.Time):
+		update.Status = ScheduledTransferCompleted
+	default:
+		update.NextRunAt = next
+	}
+
+	_, err = q.UpdateScheduledTransfer(ctx, update)
+	return err
+}
+
+var (
+	// ErrScheduledTransferClosed is returned when changing a scheduled
+	// transfer that is completed or cancelled.
+	ErrScheduledTransferClosed = errors.New("scheduled transfer is closed")
+	// ErrInvalidSchedule is returned when the schedule can't be parsed.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	// ErrNoRunBeforeEnd is returned when the next run would come after the
+	// end of the scheduled transfer.
+	ErrNoRunBeforeEnd = errors.New("schedule has no run before end_at")
+)
+
+// EditScheduledTransferParams sets the fields to change, the others are kept.
+type EditScheduledTransferParams struct {
+	ID       int64          `json:"id"`
+	Amount   sql.NullInt64  `json:"amount"`
+	Schedule sql.NullString `json:"schedule"`
+	EndAt    sql.NullTime   `json:"end_at"`
+	Status   sql.NullString `json:"status"`
+	Now      time.Time      `json:"now"`
+}
+
+// EditScheduledTransfer changes an active or paused scheduled transfer. The
+// row is locked while it changes, so it can't race RunScheduledTransfers
+// moving it to its next occurrence. Resuming it or changing its schedule
+// moves the next run to the first occurrence after Now, otherwise the next
+// run stays the one in the row.
+func (store *SQLStore) EditScheduledTransfer(ctx context.Context, arg EditScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+
+	err := store.execTx(ctx, sql.LevelDefault, func(q *Queries) error {
+		current, err := q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if current.Status != ScheduledTransferActive && current.Status != ScheduledTransferPaused {
+			return ErrScheduledTransferClosed
+		}
+
+		update := UpdateScheduledTransferParams{
+			ID:        current.ID,
+			Amount:    current.Amount,
+			Schedule:  current.Schedule,
+			NextRunAt: current.NextRunAt,
+			EndAt:     current.EndAt,
+			Status:    current.Status,
+		}
+		if arg.Amount.Valid {
+			update.Amount = arg.Amount.Int64
+		}
+		if arg.Schedule.Valid {
+			update.Schedule = arg.Schedule.String
+		}
+		if arg.EndAt.Valid {
+			update.EndAt = arg.EndAt
+		}
+		if arg.Status.Valid {
+			update.Status = arg.Status.String
+		}
+
+		resumed := current.Status == ScheduledTransferPaused && update.Status == ScheduledTransferActive
+		if arg.Schedule.Valid || resumed {
+			next, err := util.NextScheduledTime(update.Schedule, arg.Now)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+			}
+			update.NextRunAt = next
+		}
+
+		if update.Status != ScheduledTransferCancelled && update.EndAt.Valid && update.NextRunAt.After(update.EndAt.Time) {
+			return ErrNoRunBeforeEnd
+		}
+
+		scheduled, err = q.UpdateScheduledTransfer(ctx, update)
+		return err
+	})
+
+	return scheduled, err
+}
@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, nextRunAt time.Time, endAt sql.NullTime) ScheduledTransfer {
+	account1 := createFundedAccount(t, randomCurrency(t))
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+		Schedule:      "@every 1h",
+		NextRunAt:     nextRunAt,
+		EndAt:         endAt,
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, scheduled.Owner)
+	require.Equal(t, arg.Schedule, scheduled.Schedule)
+	require.Equal(t, ScheduledTransferActive, scheduled.Status)
+	require.WithinDuration(t, arg.NextRunAt, scheduled.NextRunAt, time.Second)
+
+	return scheduled
+}
+
+func findScheduledTransferRun(runs []ScheduledTransferRun, scheduledTransferID int64) (ScheduledTransferRun, bool) {
+	for _, run := range runs {
+		if run.ScheduledTransferID == scheduledTransferID {
+			return run, true
+		}
+	}
+	return ScheduledTransferRun{}, false
+}
+
+func TestRunScheduledTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// missed several runs, they collapse into one
+	recurring := createRandomScheduledTransfer(t, now.Add(-3*time.Hour), sql.NullTime{})
+	// the next occurrence falls after its end
+	ending := createRandomScheduledTransfer(t, now.Add(-time.Minute), sql.NullTime{Time: now.Add(time.Minute), Valid: true})
+	notDue := createRandomScheduledTransfer(t, now.Add(time.Hour), sql.NullTime{})
+
+	// more than the source account holds, the run fails but the others go on
+	unfunded := createRandomScheduledTransfer(t, now.Add(-time.Minute), sql.NullTime{})
+	_, err := store.EditScheduledTransfer(context.Background(), EditScheduledTransferParams{
+		ID:     unfunded.ID,
+		Amount: sql.NullInt64{Int64: 1_000_000, Valid: true},
+		Now:    now,
+	})
+	require.NoError(t, err)
+
+	fromAccount, err := store.GetAccount(context.Background(), recurring.FromAccountID)
+	require.NoError(t, err)
+
+	runs, err := store.RunScheduledTransfers(context.Background(), RunScheduledTransfersParams{
+		Now:       now,
+		BatchSize: 1000,
+	})
+	require.NoError(t, err)
+
+	recurringRun, ok := findScheduledTransferRun(runs, recurring.ID)
+	require.True(t, ok)
+	require.Equal(t, ScheduledRunSucceeded, recurringRun.Status)
+	require.WithinDuration(t, recurring.NextRunAt, recurringRun.ScheduledFor, time.Second)
+	require.True(t, recurringRun.TransferID.Valid)
+
+	transfer, err := store.GetTransfer(context.Background(), recurringRun.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, recurring.Amount, transfer.Amount)
+
+	updatedFromAccount, err := store.GetAccount(context.Background(), recurring.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-recurring.Amount, updatedFromAccount.Balance)
+
+	_, ok = findScheduledTransferRun(runs, ending.ID)
+	require.True(t, ok)
+
+	_, ok = findScheduledTransferRun(runs, notDue.ID)
+	require.False(t, ok)
+
+	unfundedRun, ok := findScheduledTransferRun(runs, unfunded.ID)
+	require.True(t, ok)
+	require.Equal(t, ScheduledRunFailed, unfundedRun.Status)
+	require.False(t, unfundedRun.TransferID.Valid)
+	require.Equal(t, ErrInsufficientFunds.Error(), unfundedRun.Error.String)
+
+	updatedRecurring, err := store.GetScheduledTransfer(context.Background(), recurring.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferActive, updatedRecurring.Status)
+	require.True(t, updatedRecurring.NextRunAt.After(now))
+
+	updatedEnding, err := store.GetScheduledTransfer(context.Background(), ending.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferCompleted, updatedEnding.Status)
+
+	updatedUnfunded, err := store.GetScheduledTransfer(context.Background(), unfunded.ID)
+	require.NoError(t, err)
+	require.True(t, updatedUnfunded.NextRunAt.After(now))
+
+	// each occurrence runs once
+	runs, err = store.RunScheduledTransfers(context.Background(), RunScheduledTransfersParams{
+		Now:       now,
+		BatchSize: 1000,
+	})
+	require.NoError(t, err)
+
+	_, ok = findScheduledTransferRun(runs, recurring.ID)
+	require.False(t, ok)
+	_, ok = findScheduledTransferRun(runs, ending.ID)
+	require.False(t, ok)
+}
+
+func TestScheduledRunError(t *testing.T) {
+	require.Equal(t, ErrInsufficientFunds.Error(), scheduledRunError(fmt.Errorf("transfer: %w", ErrInsufficientFunds)))
+	require.Equal(t, ErrAccountNotFound.Error(), scheduledRunError(ErrAccountNotFound))
+	require.Equal(t, scheduledRunInternalError, scheduledRunError(&pq.Error{Code: "23514", Message: "new row violates check constraint"}))
+	require.Equal(t, scheduledRunInternalError, scheduledRunError(sql.ErrConnDone))
+}
+
+func TestListScheduledTransferRuns(t *testing.T) {
+	scheduled := createRandomScheduledTransfer(t, time.Now(), sql.NullTime{})
+
+	succeeded, err := testQueries.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledFor:        scheduled.NextRunAt,
+		Status:              ScheduledRunSucceeded,
+		TransferID:          sql.NullInt64{Int64: createRandomTransfer(createRandomAccount(t), createRandomAccount(t)).ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	failed, err := testQueries.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledFor:        scheduled.NextRunAt.Add(time.Hour),
+		Status:              ScheduledRunFailed,
+		Error:               sql.NullString{String: ErrInsufficientFunds.Error(), Valid: true},
+	})
+	require.NoError(t, err)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, succeeded.ID, runs[0].ID)
+	require.Equal(t, failed.ID, runs[1].ID)
+	require.Equal(t, ErrInsufficientFunds.Error(), runs[1].Error.String)
+
+	runs, err = testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		ID:                  succeeded.ID,
+		Limit:               5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, failed.ID, runs[0].ID)
+}
+
+func TestEditScheduledTransfer(t *testing.T) {
+	store := NewStore(testDB)
+	now := time.Now().UTC().Truncate(time.Second)
+	scheduled := createRandomScheduledTransfer(t, now.Add(-time.Minute), sql.NullTime{})
+
+	// the worker moves the next run forward
+	runs, err := store.RunScheduledTransfers(context.Background(), RunScheduledTransfersParams{
+		Now:       now,
+		BatchSize: 1000,
+	})
+	require.NoError(t, err)
+	_, ok := findScheduledTransferRun(runs, scheduled.ID)
+	require.True(t, ok)
+
+	claimedScheduled, err := store.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.True(t, claimedScheduled.NextRunAt.After(now))
+
+	// editing the amount keeps the next run the worker stored
+	edited, err := store.EditScheduledTransfer(context.Background(), EditScheduledTransferParams{
+		ID:     scheduled.ID,
+		Amount: sql.NullInt64{Int64: scheduled.Amount + 1, Valid: true},
+		Now:    now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, scheduled.Amount+1, edited.Amount)
+	require.WithinDuration(t, claimedScheduled.NextRunAt, edited.NextRunAt, time.Second)
+
+	_, err = store.EditScheduledTransfer(context.Background(), EditScheduledTransferParams{
+		ID:       scheduled.ID,
+		Schedule: sql.NullString{String: "every day", Valid: true},
+		Now:      now,
+	})
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+
+	cancelled, err := store.EditScheduledTransfer(context.Background(), EditScheduledTransferParams{
+		ID:     scheduled.ID,
+		Status: sql.NullString{String: ScheduledTransferCancelled, Valid: true},
+		Now:    now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferCancelled, cancelled.Status)
+
+	_, err = store.EditScheduledTransfer(context.Background(), EditScheduledTransferParams{
+		ID:     scheduled.ID,
+		Amount: sql.NullInt64{Int64: 1, Valid: true},
+		Now:    now,
+	})
+	require.ErrorIs(t, err, ErrScheduledTransferClosed)
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"github/leoflalv/bank-api/util"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T) Session {
+	user := createRandomUser()
+
+	arg := CreateSessionParams{
+		ID:               uuid.New(),
+		Username:         user.Username,
+		RefreshTokenHash: util.HashToken(util.RandomString(32)),
+		UserAgent:        "test",
+		ClientIp:         "127.0.0.1",
+		IsBlocked:        false,
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	user := createRandomUser()
+
+	arg := CreateSessionParams{
+		ID:               uuid.New(),
+		Username:         user.Username,
+		RefreshTokenHash: util.HashToken(util.RandomString(32)),
+		UserAgent:        "test",
+		ClientIp:         "127.0.0.1",
+		IsBlocked:        false,
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.RefreshTokenHash, session.RefreshTokenHash)
+	require.Equal(t, arg.UserAgent, session.UserAgent)
+	require.Equal(t, arg.ClientIp, session.ClientIp)
+	require.False(t, session.IsBlocked)
+	require.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+	require.NotZero(t, session.CreatedAt)
+}
+
+func TestGetSession(t *testing.T) {
+	session1 := createRandomSession(t)
+
+	session2, err := testQueries.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, session2)
+
+	require.Equal(t, session1.ID, session2.ID)
+	require.Equal(t, session1.Username, session2.Username)
+	require.Equal(t, session1.RefreshTokenHash, session2.RefreshTokenHash)
+	require.Equal(t, session1.IsBlocked, session2.IsBlocked)
+	require.WithinDuration(t, session1.ExpiresAt, session2.ExpiresAt, time.Second)
+	require.WithinDuration(t, session1.CreatedAt, session2.CreatedAt, time.Second)
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// statementFetchSize is how many entries are read from the statement cursor
+// at a time.
+const statementFetchSize = 500
+
+// AccountStatementParams selects the entries created in [From, To).
+type AccountStatementParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// AccountStatement is the account and its balance at both ends of the
+// statement period.
+type AccountStatement struct {
+	Account        Account   `json:"account"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance int64     `json:"opening_balance"`
+	ClosingBalance int64     `json:"closing_balance"`
+}
+
+// StatementEntry is an entry with the account balance right after it.
+type StatementEntry struct {
+	Entry
+	Balance int64 `json:"balance"`
+}
+
+// StatementWriter receives a statement as it's read. Open is called before
+// the first entry and Close after the last one, both with the same
+// statement.
+type StatementWriter interface {
+	Open(statement AccountStatement) error
+	Entry(entry StatementEntry) error
+	Close(statement AccountStatement) error
+}
+
+// WriteAccountStatement streams the entries of an account in the statement
+// period to w, oldest first. The entries are read through a cursor in a
+// repeatable read transaction, so the balances stay consistent with the
+// entries without loading the whole period in memory.
+func (store *SQLStore) WriteAccountStatement(ctx context.Context, arg AccountStatementParams, w StatementWriter) error {
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	// The transaction is read only, there's nothing to commit.
+	defer tx.Rollback()
+
+	q := New(tracedDBTX{db: tx})
+	account, err := q.GetAccount(ctx, arg.AccountID)
+	if err != nil {
+		return accountError(err)
+	}
+
+	// The balance is the sum of every entry, the balance at a point in time
+	// is the current one minus what was booked after it.
+	sinceFrom, err := q.SumAccountEntriesSince(ctx, SumAccountEntriesSinceParams{
+		AccountID: arg.AccountID,
+		Since:     arg.From,
+	})
+	if err != nil {
+		return err
+	}
+
+	sinceTo, err := q.SumAccountEntriesSince(ctx, SumAccountEntriesSinceParams{
+		AccountID: arg.AccountID,
+		Since:     arg.To,
+	})
+	if err != nil {
+		return err
+	}
+
+	statement := AccountStatement{
+		Account:        account,
+		From:           arg.From,
+		To:             arg.To,
+		OpeningBalance: account.Balance - sinceFrom,
+		ClosingBalance: account.Balance - sinceTo,
+	}
+
+	if err := w.Open(statement); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, declareStatementEntries, arg.AccountID, arg.From, arg.To)
+	if err != nil {
+		return fmt.Errorf("declare statement cursor: %w", err)
+	}
+
+	balance := statement.OpeningBalance
+	for {
+		n, err := fetchStatementEntries(ctx, tx, func(entry Entry) error {
+			balance += entry.Amount
+			return w.Entry(StatementEntry{Entry: entry, Balance: balance})
+		})
+		if err != nil {
+			return err
+		}
+
+		if n < statementFetchSize {
+			break
+		}
+	}
+
+	return w.Close(statement)
+}
+
+const declareStatementEntries = `
+declare statement_entries no scroll cursor for
+select id, account_id, amount, created_at, transfer_id
+from entries
+where account_id = $1
+  and created_at >= $2
+  and created_at < $3
+order by created_at, id
+`
+
+var fetchStatementEntriesQuery = fmt.Sprintf("fetch forward %d from statement_entries", statementFetchSize)
+
+// fetchStatementEntries reads the next batch of the statement cursor and
+// returns how many entries it had.
+func fetchStatementEntries(ctx context.Context, tx *sql.Tx, fn func(Entry) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetchStatementEntriesQuery)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return n, err
+		}
+
+		n++
+		if err := fn(i); err != nil {
+			return n, err
+		}
+	}
+
+	return n, rows.Err()
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStatementWriter keeps what it's given to check it afterwards.
+type recordingStatementWriter struct {
+	opened  AccountStatement
+	entries []StatementEntry
+	closed  AccountStatement
+}
+
+func (w *recordingStatementWriter) Open(statement AccountStatement) error {
+	w.opened = statement
+	return nil
+}
+
+func (w *recordingStatementWriter) Entry(entry StatementEntry) error {
+	w.entries = append(w.entries, entry)
+	return nil
+}
+
+func (w *recordingStatementWriter) Close(statement AccountStatement) error {
+	w.closed = statement
+	return nil
+}
+
+func TestWriteAccountStatement(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	adjust := func(amount int64) Entry {
+		result, err := store.AdjustBalance(context.Background(), AdjustBalanceParams{
+			AccountID: account.ID,
+			Amount:    amount,
+			Reason:    "statement test",
+			CreatedBy: account.Owner,
+		})
+		require.NoError(t, err)
+		return result.Entry
+	}
+
+	before := adjust(-10)
+	from := time.Now()
+	in1 := adjust(25)
+	in2 := adjust(-5)
+	to := time.Now()
+	adjust(7)
+
+	w := &recordingStatementWriter{}
+	err := store.WriteAccountStatement(context.Background(), AccountStatementParams{
+		AccountID: account.ID,
+		From:      from,
+		To:        to,
+	}, w)
+	require.NoError(t, err)
+
+	opening := account.Balance + before.Amount
+	require.Equal(t, account.ID, w.opened.Account.ID)
+	require.Equal(t, opening, w.opened.OpeningBalance)
+	require.Equal(t, opening+in1.Amount+in2.Amount, w.opened.ClosingBalance)
+	require.Equal(t, w.opened, w.closed)
+
+	require.Len(t, w.entries, 2)
+	require.Equal(t, in1.ID, w.entries[0].ID)
+	require.Equal(t, opening+in1.Amount, w.entries[0].Balance)
+	require.Equal(t, in2.ID, w.entries[1].ID)
+	require.Equal(t, w.closed.ClosingBalance, w.entries[1].Balance)
+}
+
+func TestWriteAccountStatementEmptyPeriod(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	w := &recordingStatementWriter{}
+	err := store.WriteAccountStatement(context.Background(), AccountStatementParams{
+		AccountID: account.ID,
+		From:      time.Now(),
+		To:        time.Now().Add(time.Hour),
+	}, w)
+	require.NoError(t, err)
+
+	require.Empty(t, w.entries)
+	require.Equal(t, account.Balance, w.closed.OpeningBalance)
+	require.Equal(t, account.Balance, w.closed.ClosingBalance)
+}
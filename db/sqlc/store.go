@@ -3,40 +3,281 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Store interface {
 	Querier
 	Transaction(ctx context.Context, arg TransactionParams) (TransactionResult, error)
+	IdempotentTransaction(ctx context.Context, arg IdempotentTransactionParams) (IdempotentTransactionResult, error)
+	ReverseTransfer(ctx context.Context, arg ReverseTransferParams) (TransactionResult, error)
+	RevokeAllTokens(ctx context.Context, arg RevokeAllTokensParams) error
+	ChangeUserRole(ctx context.Context, arg ChangeUserRoleParams) (User, error)
+	AdjustBalance(ctx context.Context, arg AdjustBalanceParams) (AdjustBalanceResult, error)
+	ListAccountEntriesPage(ctx context.Context, arg HistoryPageParams) ([]Entry, error)
+	ListAccountTransfersPage(ctx context.Context, arg HistoryPageParams) ([]Transfer, error)
+	StartPayment(ctx context.Context, arg StartPaymentParams) (Payment, error)
+	SettlePayment(ctx context.Context, arg SettlePaymentParams) (Payment, error)
+	RunScheduledTransfers(ctx context.Context, arg RunScheduledTransfersParams) ([]ScheduledTransferRun, error)
+	EditScheduledTransfer(ctx context.Context, arg EditScheduledTransferParams) (ScheduledTransfer, error)
+	AuthorizeHold(ctx context.Context, arg AuthorizeHoldParams) (Hold, error)
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error)
+	VoidHold(ctx context.Context, id int64) (Hold, error)
+	ReleaseExpiredHolds(ctx context.Context, arg ReleaseExpiredHoldsParams) ([]Hold, error)
+	WriteAccountStatement(ctx context.Context, arg AccountStatementParams, w StatementWriter) error
+	Reconcile(ctx context.Context) (Reconciliation, error)
+	RetryStats() RetryStats
+	PoolStats() sql.DBStats
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
+}
+
+// RetryPolicy is how many times a transaction aborted by a serialization
+// failure or a deadlock runs again. The wait before each retry doubles from
+// BaseDelay up to MaxDelay, with jitter so the transactions that conflicted
+// don't collide again.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  10 * time.Millisecond,
+	MaxDelay:   500 * time.Millisecond,
+}
+
+// backoff returns the wait before the retry following attempt, counting
+// from 0.
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.MaxDelay
+	if attempt < 32 {
+		if doubled := policy.BaseDelay << attempt; doubled > 0 && doubled < policy.MaxDelay {
+			delay = doubled
+		}
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// RetryStats counts the transactions run again since the store was created,
+// by the error that aborted them, and the ones still failing after the last
+// retry.
+type RetryStats struct {
+	SerializationFailures int64 `json:"serialization_failures"`
+	Deadlocks             int64 `json:"deadlocks"`
+	Exhausted             int64 `json:"exhausted"`
+}
+
+// StoreOptions tunes the transactions run by the store.
+type StoreOptions struct {
+	Retry RetryPolicy
+	// TransferIsolation is the isolation level of the transactions moving
+	// money between accounts. The default leaves it to the database, the
+	// balances are guarded by row locks either way; a stricter level adds
+	// serialization failures, which run again following Retry.
+	TransferIsolation sql.IsolationLevel
 }
 
 type SQLStore struct {
-	*Queries
-	db *sql.DB
+	translatedQueries
+	db                *sql.DB
+	retry             RetryPolicy
+	transferIsolation sql.IsolationLevel
+
+	serializationRetries atomic.Int64
+	deadlockRetries      atomic.Int64
+	exhaustedRetries     atomic.Int64
 }
 
 func NewStore(db *sql.DB) Store {
+	return NewStoreWithRetryPolicy(db, DefaultRetryPolicy)
+}
+
+func NewStoreWithRetryPolicy(db *sql.DB, retry RetryPolicy) Store {
+	return NewStoreWithOptions(db, StoreOptions{Retry: retry})
+}
+
+func NewStoreWithOptions(db *sql.DB, opts StoreOptions) Store {
 	return &SQLStore{
-		db:      db,
-		Queries: New(db),
+		db:                db,
+		translatedQueries: translatedQueries{q: New(tracedDBTX{db: db})},
+		retry:             opts.Retry,
+		transferIsolation: opts.TransferIsolation,
 	}
 }
 
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// isolationLevels are the levels ParseIsolationLevel accepts.
+var isolationLevels = []sql.IsolationLevel{
+	sql.LevelDefault,
+	sql.LevelReadCommitted,
+	sql.LevelRepeatableRead,
+	sql.LevelSerializable,
+}
+
+// ParseIsolationLevel returns the isolation level named like "read committed"
+// or "serializable", in any case. An empty name is the default level.
+func ParseIsolationLevel(name string) (sql.IsolationLevel, error) {
+	if name == "" {
+		return sql.LevelDefault, nil
+	}
+
+	for _, level := range isolationLevels {
+		if strings.EqualFold(name, level.String()) {
+			return level, nil
+		}
+	}
+	return sql.LevelDefault, fmt.Errorf("unsupported isolation level %q", name)
+}
+
+func (store *SQLStore) RetryStats() RetryStats {
+	return RetryStats{
+		SerializationFailures: store.serializationRetries.Load(),
+		Deadlocks:             store.deadlockRetries.Load(),
+		Exhausted:             store.exhaustedRetries.Load(),
+	}
+}
+
+// PoolStats returns the stats of the connection pool.
+func (store *SQLStore) PoolStats() sql.DBStats {
+	return store.db.Stats()
+}
+
+// execTx runs fn in a transaction with the isolation level of the operation,
+// committing it when fn succeeds. Transactions aborted by a serialization
+// failure or a deadlock run again following the retry policy, so fn must
+// only have effects through q. The errors returned are translated into the
+// typed errors of the package.
+func (store *SQLStore) execTx(ctx context.Context, isolation sql.IsolationLevel, fn func(*Queries) error) (err error) {
+	ctx, span := tracer().Start(ctx, "execTx", trace.WithAttributes(
+		attribute.String("db.transaction.isolation", isolation.String()),
+	))
+	attempts := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("db.transaction.attempts", attempts))
+		recordSpanError(span, err)
+		span.End()
+	}()
+
+	for attempt := 0; ; attempt++ {
+		attempts++
+		err := store.runTx(ctx, isolation, fn)
+
+		retries := store.retryCounter(err)
+		if retries == nil {
+			return err
+		}
+
+		if attempt >= store.retry.MaxRetries {
+			store.exhaustedRetries.Add(1)
+			slog.WarnContext(ctx, "transaction retries exhausted", "attempts", attempt+1, "error", err)
+			return err
+		}
+		retries.Add(1)
+		slog.DebugContext(ctx, "retrying transaction", "attempt", attempt+1, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(store.retry.backoff(attempt)):
+		}
+	}
+}
+
+// runTx runs one attempt of the transaction. Its span holds the spans of
+// the queries and of BEGIN, COMMIT and ROLLBACK.
+func (store *SQLStore) runTx(ctx context.Context, isolation sql.IsolationLevel, fn func(*Queries) error) error {
+	start := time.Now()
+
+	ctx, span := tracer().Start(ctx, "transaction")
+	defer span.End()
+
+	var tx *sql.Tx
+	err := traceTxStep(ctx, "BEGIN", func(ctx context.Context) (err error) {
+		tx, err = store.db.BeginTx(ctx, &sql.TxOptions{Isolation: isolation})
+		return err
+	})
 	if err != nil {
+		recordSpanError(span, err)
 		return err
 	}
 
-	q := New(tx)
-	err = fn(q)
+	q := New(tracedDBTX{db: tx, tx: span})
+	err = TranslateError(fn(q))
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		recordSpanError(span, err)
+		txRollbacks.Inc()
+		observeTx(isolation, txRolledBack, start)
+		rbErr := traceTxStep(ctx, "ROLLBACK", func(context.Context) error {
+			return tx.Rollback()
+		})
+		if rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
 		}
 		return err
 	}
 
-	return tx.Commit()
+	err = TranslateError(traceTxStep(ctx, "COMMIT", func(context.Context) error {
+		return tx.Commit()
+	}))
+	if err != nil {
+		recordSpanError(span, err)
+		observeTx(isolation, txCommitFailed, start)
+		return err
+	}
+	observeTx(isolation, txCommitted, start)
+	return nil
+}
+
+// traceTxStep runs a statement controlling the transaction in its own span.
+func traceTxStep(ctx context.Context, name string, step func(context.Context) error) error {
+	ctx, span := tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql")))
+	defer span.End()
+
+	err := step(ctx)
+	recordSpanError(span, err)
+	return err
+}
+
+// retryCounter returns the counter of the error when the transaction it
+// aborted can run again, nil otherwise.
+func (store *SQLStore) retryCounter(err error) *atomic.Int64 {
+	switch retryableError(err) {
+	case "serialization_failure":
+		return &store.serializationRetries
+	case "deadlock_detected":
+		return &store.deadlockRetries
+	}
+	return nil
+}
+
+// retryableError returns the name of the error code when err aborted a
+// transaction that can run again, an empty string otherwise.
+func retryableError(err error) string {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return ""
+	}
+
+	switch name := pqErr.Code.Name(); name {
+	case "serialization_failure", "deadlock_detected":
+		return name
+	}
+	return ""
 }
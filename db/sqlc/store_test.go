@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond}
+
+	for attempt := 0; attempt < 40; attempt++ {
+		delay := policy.BaseDelay << attempt
+		if attempt >= 32 || delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+
+		backoff := policy.backoff(attempt)
+		require.GreaterOrEqual(t, backoff, delay/2)
+		require.LessOrEqual(t, backoff, delay)
+	}
+
+	require.Zero(t, RetryPolicy{}.backoff(3))
+}
+
+func TestRetryCounter(t *testing.T) {
+	store := NewStore(nil).(*SQLStore)
+
+	require.Equal(t, &store.serializationRetries, store.retryCounter(&pq.Error{Code: "40001"}))
+	require.Equal(t, &store.deadlockRetries, store.retryCounter(TranslateError(&pq.Error{Code: "40P01"})))
+	require.Nil(t, store.retryCounter(&pq.Error{Code: "23505"}))
+	require.Nil(t, store.retryCounter(sql.ErrNoRows))
+	require.Nil(t, store.retryCounter(nil))
+}
+
+func TestParseIsolationLevel(t *testing.T) {
+	level, err := ParseIsolationLevel("")
+	require.NoError(t, err)
+	require.Equal(t, sql.LevelDefault, level)
+
+	level, err = ParseIsolationLevel("Serializable")
+	require.NoError(t, err)
+	require.Equal(t, sql.LevelSerializable, level)
+
+	level, err = ParseIsolationLevel("read committed")
+	require.NoError(t, err)
+	require.Equal(t, sql.LevelReadCommitted, level)
+
+	_, err = ParseIsolationLevel("snapshot")
+	require.Error(t, err)
+}
+
+func TestExecTxRetries(t *testing.T) {
+	store := NewStoreWithRetryPolicy(testDB, RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}).(*SQLStore)
+
+	attempts := 0
+	err := store.execTx(context.Background(), sql.LevelSerializable, func(q *Queries) error {
+		attempts++
+		if attempts == 1 {
+			return &pq.Error{Code: "40P01"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, RetryStats{Deadlocks: 1}, store.RetryStats())
+
+	attempts = 0
+	err = store.execTx(context.Background(), sql.LevelSerializable, func(q *Queries) error {
+		attempts++
+		return &pq.Error{Code: "40001"}
+	})
+	require.Error(t, err)
+	require.Equal(t, 3, attempts)
+	require.Equal(t, RetryStats{SerializationFailures: 2, Deadlocks: 1, Exhausted: 1}, store.RetryStats())
+
+	attempts = 0
+	err = store.execTx(context.Background(), sql.LevelSerializable, func(q *Queries) error {
+		attempts++
+		return ErrInsufficientFunds
+	})
+	require.True(t, errors.Is(err, ErrInsufficientFunds))
+	require.Equal(t, 1, attempts)
+}
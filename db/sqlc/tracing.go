@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github/leoflalv/bank-api/db"
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// tracedDBTX runs the queries in a span named after the sqlc query. Inside a
+// transaction the spans are children of the span of the transaction, since
+// the functions given to execTx use the context of their caller.
+type tracedDBTX struct {
+	db DBTX
+	tx trace.Span
+}
+
+func (t tracedDBTX) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	if t.tx != nil {
+		ctx = trace.ContextWithSpan(ctx, t.tx)
+	}
+
+	return tracer().Start(ctx, queryName(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.query.text", query),
+		),
+	)
+}
+
+func (t tracedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	defer span.End()
+
+	result, err := t.db.ExecContext(ctx, query, args...)
+	recordSpanError(span, err)
+	return result, err
+}
+
+func (t tracedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := t.start(ctx, query)
+	defer span.End()
+
+	stmt, err := t.db.PrepareContext(ctx, query)
+	recordSpanError(span, err)
+	return stmt, err
+}
+
+// QueryContext returns before the rows are read, the span lasts until they
+// are closed when the database was opened with Open. Otherwise it only covers
+// running the query.
+func (t tracedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query)
+
+	pending := &rowsSpan{span: span}
+	rows, err := t.db.QueryContext(context.WithValue(ctx, rowsSpanKey{}, pending), query, args...)
+	recordSpanError(span, err)
+	if err != nil || !pending.claimed {
+		span.End()
+	}
+	return rows, err
+}
+
+func (t tracedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := t.start(ctx, query)
+	defer span.End()
+
+	row := t.db.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); err != sql.ErrNoRows {
+		recordSpanError(span, err)
+	}
+	return row
+}
+
+// queryName returns the name sqlc writes at the start of the queries, like
+// GetAccount, or the first word of other statements.
+func queryName(query string) string {
+	query = strings.TrimSpace(query)
+	if rest, ok := strings.CutPrefix(query, "-- name:"); ok {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+
+	if fields := strings.Fields(query); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return "query"
+}
+
+func recordSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// failingDBTX fails every statement without a database.
+type failingDBTX struct{}
+
+var errStatementFailed = errors.New("statement failed")
+
+func (failingDBTX) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errStatementFailed
+}
+
+func (failingDBTX) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errStatementFailed
+}
+
+func (failingDBTX) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errStatementFailed
+}
+
+func (failingDBTX) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+// rowsDriver answers every query with the ids 1 and 2, without a database.
+type rowsDriver struct{}
+
+func (rowsDriver) Open(string) (driver.Conn, error) { return rowsConn{}, nil }
+
+func (rowsDriver) OpenConnector(string) (driver.Connector, error) { return rowsConnector{}, nil }
+
+type rowsConnector struct{}
+
+func (rowsConnector) Connect(context.Context) (driver.Conn, error) { return rowsConn{}, nil }
+
+func (rowsConnector) Driver() driver.Driver { return rowsDriver{} }
+
+type rowsConn struct{}
+
+func (rowsConn) Prepare(string) (driver.Stmt, error) { return nil, errStatementFailed }
+
+func (rowsConn) Close() error { return nil }
+
+func (rowsConn) Begin() (driver.Tx, error) { return nil, errStatementFailed }
+
+func (rowsConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &idRows{ids: []int64{1, 2}}, nil
+}
+
+type idRows struct {
+	ids []int64
+}
+
+func (*idRows) Columns() []string { return []string{"id"} }
+
+func (*idRows) Close() error { return nil }
+
+func (r *idRows) Next(dest []driver.Value) error {
+	if len(r.ids) == 0 {
+		return io.EOF
+	}
+	dest[0], r.ids = r.ids[0], r.ids[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("rows", rowsDriver{})
+}
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetAccount", queryName(getAccount))
+	require.Equal(t, "SELECT", queryName("  select version, dirty FROM schema_migrations"))
+	require.Equal(t, "query", queryName(""))
+}
+
+func TestTracedDBTX(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(provider)
+
+	ctx, request := otel.Tracer("test").Start(context.Background(), "request")
+	_, tx := otel.Tracer("test").Start(ctx, "transaction")
+
+	// Queries run by execTx functions get the context of the request, their
+	// spans still belong to the transaction.
+	q := New(tracedDBTX{db: failingDBTX{}, tx: tx})
+	err := q.DeleteAccount(ctx, 1)
+	require.ErrorIs(t, err, errStatementFailed)
+
+	_, err = New(tracedDBTX{db: failingDBTX{}}).ListAccounts(ctx, ListAccountsParams{Limit: 1})
+	require.ErrorIs(t, err, errStatementFailed)
+
+	tx.End()
+	request.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	deleteSpan, listSpan := spans[0], spans[1]
+	require.Equal(t, "DeleteAccount", deleteSpan.Name())
+	require.Equal(t, tx.SpanContext().SpanID(), deleteSpan.Parent().SpanID())
+	require.Equal(t, codes.Error, deleteSpan.Status().Code)
+
+	require.Equal(t, "ListAccounts", listSpan.Name())
+	require.Equal(t, request.SpanContext().SpanID(), listSpan.Parent().SpanID())
+}
+
+func TestTracedDBTXQueryEndsWithRows(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(provider)
+
+	conn, err := Open("rows", "")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	rows, err := tracedDBTX{db: conn}.QueryContext(context.Background(), "-- name: ListIDs :many\nSELECT id FROM ids")
+	require.NoError(t, err)
+	require.Empty(t, recorder.Ended(), "the span ended before the rows were read")
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Close())
+	require.Equal(t, []int64{1, 2}, ids)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "ListIDs", spans[0].Name())
+
+	// A database opened without Open can't tell when the rows are closed,
+	// the span only covers running the query.
+	plain := sql.OpenDB(rowsConnector{})
+	defer plain.Close()
+
+	rows, err = tracedDBTX{db: plain}.QueryContext(context.Background(), "SELECT id FROM ids")
+	require.NoError(t, err)
+	defer rows.Close()
+	require.Len(t, recorder.Ended(), 2)
+}
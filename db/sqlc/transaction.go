@@ -1,11 +1,29 @@
 package db
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
 
+// ErrAmountTooSmall is returned when the converted amount of a transfer
+// rounds down to nothing in the destination currency.
+var ErrAmountTooSmall = errors.New("amount too small to convert")
+
+// TransactionParams moves Amount, in the source account currency, between
+// two accounts. ExchangeRate and RateAt are only set when the accounts hold
+// different currencies.
 type TransactionParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExchangeRate  float64   `json:"exchange_rate"`
+	RateAt        time.Time `json:"rate_at"`
 }
 
 type TransactionResult struct {
@@ -19,48 +37,206 @@ type TransactionResult struct {
 func (store *SQLStore) Transaction(ctx context.Context, arg TransactionParams) (TransactionResult, error) {
 	var result TransactionResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execTx(ctx, store.transferIsolation, func(q *Queries) error {
 		var err error
+		result, err = transfer(ctx, q, arg)
+		return err
+	})
+	if err == nil {
+		countTransfer(transferKindTransfer, result)
+	}
 
-		// Create tansfer
-		result.Tranfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+	return result, err
+}
 
-		// Create entries
+// transfer moves the money between the accounts using q, which must be bound
+// to a transaction.
+func transfer(ctx context.Context, q *Queries, arg TransactionParams) (TransactionResult, error) {
+	var result TransactionResult
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
+	// Lock accounts and check funds
+	fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	if fromAccount.AvailableBalance-arg.Amount < -fromAccount.OverdraftLimit {
+		return result, ErrInsufficientFunds
+	}
+
+	toAmount, err := convertAmount(ctx, q, fromAccount, toAccount, arg)
+	if err != nil {
+		return result, err
+	}
+
+	transferArg := CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ToAmount:      toAmount,
+		ExchangeRate:  1,
+	}
+	if fromAccount.Currency != toAccount.Currency {
+		transferArg.ExchangeRate = arg.ExchangeRate
+		transferArg.RateAt = sql.NullTime{Time: arg.RateAt, Valid: true}
+	}
+
+	return recordTransfer(ctx, q, transferArg)
+}
+
+// recordTransfer writes the transfer, its entries and the balance updates
+// without any check, both accounts must already be locked.
+func recordTransfer(ctx context.Context, q *Queries, arg CreateTransferParams) (TransactionResult, error) {
+	var result TransactionResult
+	var err error
+
+	// Create tansfer
+	result.Tranfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+
+	// Create entries
+
+	transferID := sql.NullInt64{Int64: result.Tranfer.ID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.ToAmount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Update accounts
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.ToAmount, arg.FromAccountID, -arg.Amount)
+	}
+
+	return result, err
+}
+
+// convertAmount returns the amount credited to the destination account,
+// converting it with the transfer rate when the currencies differ.
+func convertAmount(ctx context.Context, q *Queries, fromAccount Account, toAccount Account, arg TransactionParams) (int64, error) {
+	if fromAccount.Currency == toAccount.Currency {
+		if arg.ExchangeRate != 0 && arg.ExchangeRate != 1 {
+			return 0, ErrCurrencyMismatch
 		}
+		return arg.Amount, nil
+	}
+
+	if arg.ExchangeRate <= 0 || math.IsInf(arg.ExchangeRate, 0) || math.IsNaN(arg.ExchangeRate) {
+		return 0, ErrCurrencyMismatch
+	}
+
+	fromCurrency, err := q.GetCurrency(ctx, fromAccount.Currency)
+	if err != nil {
+		return 0, err
+	}
+	toCurrency, err := q.GetCurrency(ctx, toAccount.Currency)
+	if err != nil {
+		return 0, err
+	}
+
+	toAmount, err := convertMinorUnits(arg.Amount, arg.ExchangeRate, fromCurrency.Exponent, toCurrency.Exponent)
+	if err != nil {
+		return 0, err
+	}
+	if toAmount < 1 {
+		return 0, ErrAmountTooSmall
+	}
+
+	return toAmount, nil
+}
+
+// convertMinorUnits converts amount, in minor units of a currency with
+// fromExponent digits, into minor units of a currency with toExponent digits.
+// The rate is between major units, so the result is scaled by
+// 10^(toExponent-fromExponent). The rate is taken as the decimal it prints
+// as and the arithmetic is exact, only the result is rounded, half away from
+// zero.
+func convertMinorUnits(amount int64, rate float64, fromExponent, toExponent int16) (int64, error) {
+	value, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return 0, fmt.Errorf("invalid exchange rate %v", rate)
+	}
+	value.Mul(value, new(big.Rat).SetInt64(amount))
+
+	shift := int64(toExponent) - int64(fromExponent)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(shift)), nil)
+	if shift >= 0 {
+		value.Mul(value, new(big.Rat).SetInt(scale))
+	} else {
+		value.Quo(value, new(big.Rat).SetInt(scale))
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+	rounded := roundRat(value)
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("converted amount of %d overflows", amount)
+	}
+	return rounded.Int64(), nil
+}
+
+// roundRat rounds x to the nearest integer, half away from zero.
+func roundRat(x *big.Rat) *big.Int {
+	quo, rem := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(x.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(x.Sign())))
+	}
+	return quo
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// lockAccounts locks both accounts of a transfer for update, always in the
+// same id order so that concurrent opposite transfers can't deadlock.
+func lockAccounts(
+	ctx context.Context,
+	q *Queries,
+	fromAccountID int64,
+	toAccountID int64,
+) (fromAccount Account, toAccount Account, err error) {
+	if fromAccountID < toAccountID {
+		fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
 		if err != nil {
-			return err
+			return fromAccount, toAccount, accountError(err)
 		}
 
-		// Update accounts
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-		}
+		toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
+		return fromAccount, toAccount, accountError(err)
+	}
 
-		return nil
-	})
+	toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
+	if err != nil {
+		return fromAccount, toAccount, accountError(err)
+	}
 
-	return result, err
+	fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
+	return fromAccount, toAccount, accountError(err)
 }
 
 func addMoney(
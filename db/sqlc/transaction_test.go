@@ -2,7 +2,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"github/leoflalv/bank-api/util"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -10,8 +14,8 @@ import (
 func TestTransaction(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createFundedAccount(t, randomCurrency(t))
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
 
 	n := 5
 	amount := int64(10)
@@ -104,8 +108,8 @@ func TestTransaction(t *testing.T) {
 func TestTransactionDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createFundedAccount(t, randomCurrency(t))
+	account2 := createFundedAccount(t, account1.Currency)
 
 	n := 10
 	amount := int64(10)
@@ -146,3 +150,263 @@ func TestTransactionDeadlock(t *testing.T) {
 	require.True(t, account1.Balance-updatedAccount1.Balance == 0)
 	require.True(t, account2.Balance-updatedAccount2.Balance == 0)
 }
+
+func TestTransactionSerializable(t *testing.T) {
+	store := NewStoreWithOptions(testDB, StoreOptions{
+		Retry:             RetryPolicy{MaxRetries: 20, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond},
+		TransferIsolation: sql.LevelSerializable,
+	})
+
+	account1 := createFundedAccount(t, randomCurrency(t))
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	n := 10
+	amount := int64(10)
+
+	errs := make(chan error)
+
+	// the transfers conflict on the same rows, the ones aborted by a
+	// serialization failure run again until they go through
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.Transaction(context.Background(), TransactionParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			})
+
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	require.Zero(t, store.RetryStats().Exhausted)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-int64(n)*amount, updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+int64(n)*amount, updatedAccount2.Balance)
+}
+
+func TestTransactionInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	account1 = setAccountBalance(t, store, account1, 100)
+
+	n := 5
+	amount := int64(30)
+
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.Transaction(context.Background(), TransactionParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			})
+
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+
+	require.Equal(t, int(account1.Balance/amount), succeeded)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-int64(succeeded)*amount, updatedAccount1.Balance)
+	require.True(t, updatedAccount1.Balance >= 0)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+int64(succeeded)*amount, updatedAccount2.Balance)
+}
+
+func TestTransactionOverdraftLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	account1 = setAccountBalance(t, store, account1, 10)
+
+	account1, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 50,
+	})
+	require.NoError(t, err)
+
+	n := 4
+	amount := int64(20)
+
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.Transaction(context.Background(), TransactionParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			})
+
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+
+	// 10 of balance plus 50 of overdraft only cover three transfers of 20
+	require.Equal(t, 3, succeeded)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-50), updatedAccount1.Balance)
+}
+
+func TestTransactionCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, util.USD)
+	account2 := createFundedAccount(t, util.EUR)
+
+	arg := TransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		ExchangeRate:  0.9235,
+		RateAt:        time.Now().Add(-time.Hour).Truncate(time.Second),
+	}
+
+	result, err := store.Transaction(context.Background(), arg)
+	require.NoError(t, err)
+
+	// 50 * 0.9235 = 46.175, rounded to the nearest minor unit
+	toAmount := int64(46)
+
+	transfer := result.Tranfer
+	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, toAmount, transfer.ToAmount)
+	require.Equal(t, arg.ExchangeRate, transfer.ExchangeRate)
+	require.True(t, transfer.RateAt.Valid)
+	require.WithinDuration(t, arg.RateAt, transfer.RateAt.Time, time.Second)
+
+	require.Equal(t, -arg.Amount, result.FromEntry.Amount)
+	require.Equal(t, toAmount, result.ToEntry.Amount)
+
+	require.Equal(t, account1.Balance-arg.Amount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+toAmount, result.ToAccount.Balance)
+
+	// the other way round without a rate is refused
+	_, err = store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		ExchangeRate:  0.4,
+		RateAt:        time.Now(),
+	})
+	require.ErrorIs(t, err, ErrAmountTooSmall)
+}
+
+func TestConvertMinorUnits(t *testing.T) {
+	testCases := []struct {
+		name         string
+		amount       int64
+		rate         float64
+		fromExponent int16
+		toExponent   int16
+		toAmount     int64
+	}{
+		// 10.00 USD at 150 JPY per USD
+		{name: "ToFewerDigits", amount: 1000, rate: 150, fromExponent: 2, toExponent: 0, toAmount: 1500},
+		// 1500 JPY at 0.0067 USD per JPY is 10.05 USD
+		{name: "ToMoreDigits", amount: 1500, rate: 0.0067, fromExponent: 0, toExponent: 2, toAmount: 1005},
+		// 1.00 USD at 0.377 BHD per USD
+		{name: "ThreeDigits", amount: 100, rate: 0.377, fromExponent: 2, toExponent: 3, toAmount: 377},
+		// 100.5 rounds up, float64 math gives 100.49999999999999
+		{name: "HalfRoundsUp", amount: 100, rate: 1.005, fromExponent: 2, toExponent: 2, toAmount: 101},
+		{name: "BelowHalf", amount: 50, rate: 0.9235, fromExponent: 2, toExponent: 2, toAmount: 46},
+		// 0.01 USD is 1.5 JPY
+		{name: "ToFewerDigitsHalf", amount: 1, rate: 150, fromExponent: 2, toExponent: 0, toAmount: 2},
+		{name: "RoundsToNothing", amount: 1, rate: 0.0049, fromExponent: 0, toExponent: 2, toAmount: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			toAmount, err := convertMinorUnits(tc.amount, tc.rate, tc.fromExponent, tc.toExponent)
+			require.NoError(t, err)
+			require.Equal(t, tc.toAmount, toAmount)
+		})
+	}
+
+	_, err := convertMinorUnits(math.MaxInt64, 2, 2, 2)
+	require.Error(t, err)
+}
+
+func TestTransactionCurrencyExponents(t *testing.T) {
+	store := NewStore(testDB)
+
+	// disabled, so other tests don't draw it
+	_, err := testDB.Exec(`insert into currencies (code, numeric_code, exponent, enabled) values ('JPY', '392', 0, false) on conflict do nothing`)
+	require.NoError(t, err)
+
+	usd := createFundedAccount(t, util.USD)
+	jpy := createAccountWithBalance(t, "JPY", 10_000)
+
+	// 1.00 USD at 150 JPY per USD
+	result, err := store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: usd.ID,
+		ToAccountID:   jpy.ID,
+		Amount:        100,
+		ExchangeRate:  150,
+		RateAt:        time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(150), result.Tranfer.ToAmount)
+	require.Equal(t, jpy.Balance+150, result.ToAccount.Balance)
+
+	// 1000 JPY at 0.0067 USD per JPY
+	result, err = store.Transaction(context.Background(), TransactionParams{
+		FromAccountID: jpy.ID,
+		ToAccountID:   usd.ID,
+		Amount:        1000,
+		ExchangeRate:  0.0067,
+		RateAt:        time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(670), result.Tranfer.ToAmount)
+}
@@ -7,24 +7,37 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 insert into transfers 
-(from_account_id, to_account_id, amount) 
+(from_account_id, to_account_id, amount, to_amount, exchange_rate, rate_at, reversal_of) 
 values 
-($1, $2, $3) 
-returning id, from_account_id, to_account_id, amount, created_at
+($1, $2, $3, $4, $5, $6, $7) 
+returning id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ToAmount      int64         `json:"to_amount"`
+	ExchangeRate  float64       `json:"exchange_rate"`
+	RateAt        sql.NullTime  `json:"rate_at"`
+	ReversalOf    sql.NullInt64 `json:"reversal_of"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.RateAt,
+		arg.ReversalOf,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -32,12 +45,37 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RateAt,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const getReversedAmounts = `-- name: GetReversedAmounts :one
+select coalesce(sum(amount), 0)::bigint as amount, coalesce(sum(to_amount), 0)::bigint as to_amount
+from transfers
+where reversal_of = $1
+`
+
+type GetReversedAmountsRow struct {
+	Amount   int64 `json:"amount"`
+	ToAmount int64 `json:"to_amount"`
+}
+
+func (q *Queries) GetReversedAmounts(ctx context.Context, reversalOf sql.NullInt64) (GetReversedAmountsRow, error) {
+	row := q.db.QueryRowContext(ctx, getReversedAmounts, reversalOf)
+	var i GetReversedAmountsRow
+	err := row.Scan(
+		&i.Amount,
+		&i.ToAmount,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-select id, from_account_id, to_account_id, amount, created_at
+select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
 from transfers
 where id = $1
 limit 1
@@ -52,32 +90,454 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RateAt,
+		&i.ReversalOf,
 	)
 	return i, err
 }
 
-const listTransfers = `-- name: ListTransfers :many
-select id, from_account_id, to_account_id, amount, created_at
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
 from transfers
-where from_account_id = $1 or to_account_id = $2
+where id = $1
+limit 1
+for no key update
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.RateAt,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const isTransferLinked = `-- name: IsTransferLinked :one
+select exists (
+  select 1
+  from payments
+  where transfer_id = $1 or refund_transfer_id = $1
+) or exists (
+  select 1
+  from holds
+  where transfer_id = $1
+) as linked
+`
+
+func (q *Queries) IsTransferLinked(ctx context.Context, id int64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isTransferLinked, id)
+	var linked bool
+	err := row.Scan(&linked)
+	return linked, err
+}
+
+const listAccountTransfersByAmount = `-- name: ListAccountTransfersByAmount :many
+select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+from (
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where from_account_id = $1
+      and ($2::timestamptz is null or created_at >= $2::timestamptz)
+      and ($3::timestamptz is null or created_at < $3::timestamptz)
+      and ($4::text is null or $4::text = 'outgoing')
+      and ($5::bigint is null or amount >= $5::bigint)
+      and ($6::bigint is null or amount <= $6::bigint)
+      and (amount, id) > ($7::bigint, $8::bigint)
+    order by amount, id
+    limit $9
+  )
+  union all
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where to_account_id = $1
+      and ($2::timestamptz is null or created_at >= $2::timestamptz)
+      and ($3::timestamptz is null or created_at < $3::timestamptz)
+      and ($4::text is null or $4::text = 'incoming')
+      and (from_account_id <> $1 or $4::text = 'incoming')
+      and ($5::bigint is null or amount >= $5::bigint)
+      and ($6::bigint is null or amount <= $6::bigint)
+      and (amount, id) > ($7::bigint, $8::bigint)
+    order by amount, id
+    limit $9
+  )
+) as transfers
+order by amount, id
+limit $9
+`
+
+type ListAccountTransfersByAmountParams struct {
+	AccountID    int64          `json:"account_id"`
+	FromTime     sql.NullTime   `json:"from_time"`
+	ToTime       sql.NullTime   `json:"to_time"`
+	Direction    sql.NullString `json:"direction"`
+	MinAmount    sql.NullInt64  `json:"min_amount"`
+	MaxAmount    sql.NullInt64  `json:"max_amount"`
+	CursorAmount int64          `json:"cursor_amount"`
+	CursorID     int64          `json:"cursor_id"`
+	PageLimit    int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountTransfersByAmount(ctx context.Context, arg ListAccountTransfersByAmountParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfersByAmount,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorAmount,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.RateAt,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTransfersByAmountDesc = `-- name: ListAccountTransfersByAmountDesc :many
+select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+from (
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where from_account_id = $1
+      and ($2::timestamptz is null or created_at >= $2::timestamptz)
+      and ($3::timestamptz is null or created_at < $3::timestamptz)
+      and ($4::text is null or $4::text = 'outgoing')
+      and ($5::bigint is null or amount >= $5::bigint)
+      and ($6::bigint is null or amount <= $6::bigint)
+      and (amount, id) < ($7::bigint, $8::bigint)
+    order by amount desc, id desc
+    limit $9
+  )
+  union all
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where to_account_id = $1
+      and ($2::timestamptz is null or created_at >= $2::timestamptz)
+      and ($3::timestamptz is null or created_at < $3::timestamptz)
+      and ($4::text is null or $4::text = 'incoming')
+      and (from_account_id <> $1 or $4::text = 'incoming')
+      and ($5::bigint is null or amount >= $5::bigint)
+      and ($6::bigint is null or amount <= $6::bigint)
+      and (amount, id) < ($7::bigint, $8::bigint)
+    order by amount desc, id desc
+    limit $9
+  )
+) as transfers
+order by amount desc, id desc
+limit $9
+`
+
+type ListAccountTransfersByAmountDescParams struct {
+	AccountID    int64          `json:"account_id"`
+	FromTime     sql.NullTime   `json:"from_time"`
+	ToTime       sql.NullTime   `json:"to_time"`
+	Direction    sql.NullString `json:"direction"`
+	MinAmount    sql.NullInt64  `json:"min_amount"`
+	MaxAmount    sql.NullInt64  `json:"max_amount"`
+	CursorAmount int64          `json:"cursor_amount"`
+	CursorID     int64          `json:"cursor_id"`
+	PageLimit    int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountTransfersByAmountDesc(ctx context.Context, arg ListAccountTransfersByAmountDescParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfersByAmountDesc,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorAmount,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.RateAt,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTransfersByID = `-- name: ListAccountTransfersByID :many
+select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+from (
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where from_account_id = $1
+      and ($2::timestamptz is null or created_at >= $2::timestamptz)
+      and ($3::timestamptz is null or created_at < $3::timestamptz)
+      and ($4::text is null or $4::text = 'outgoing')
+      and ($5::bigint is null or amount >= $5::bigint)
+      and ($6::bigint is null or amount <= $6::bigint)
+      and id > $7
+    order by id
+    limit $8
+  )
+  union all
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where to_account_id = $1
+      and ($2::timestamptz is null or created_at >= $2::timestamptz)
+      and ($3::timestamptz is null or created_at < $3::timestamptz)
+      and ($4::text is null or $4::text = 'incoming')
+      and (from_account_id <> $1 or $4::text = 'incoming')
+      and ($5::bigint is null or amount >= $5::bigint)
+      and ($6::bigint is null or amount <= $6::bigint)
+      and id > $7
+    order by id
+    limit $8
+  )
+) as transfers
+order by id
+limit $8
+`
+
+type ListAccountTransfersByIDParams struct {
+	AccountID int64          `json:"account_id"`
+	FromTime  sql.NullTime   `json:"from_time"`
+	ToTime    sql.NullTime   `json:"to_time"`
+	Direction sql.NullString `json:"direction"`
+	MinAmount sql.NullInt64  `json:"min_amount"`
+	MaxAmount sql.NullInt64  `json:"max_amount"`
+	CursorID  int64          `json:"cursor_id"`
+	PageLimit int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountTransfersByID(ctx context.Context, arg ListAccountTransfersByIDParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfersByID,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.RateAt,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTransfersByIDDesc = `-- name: ListAccountTransfersByIDDesc :many
+select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+from (
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where from_account_id = $1
+      and ($2::timestamptz is null or created_at >= $2::timestamptz)
+      and ($3::timestamptz is null or created_at < $3::timestamptz)
+      and ($4::text is null or $4::text = 'outgoing')
+      and ($5::bigint is null or amount >= $5::bigint)
+      and ($6::bigint is null or amount <= $6::bigint)
+      and id < $7
+    order by id desc
+    limit $8
+  )
+  union all
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where to_account_id = $1
+      and ($2::timestamptz is null or created_at >= $2::timestamptz)
+      and ($3::timestamptz is null or created_at < $3::timestamptz)
+      and ($4::text is null or $4::text = 'incoming')
+      and (from_account_id <> $1 or $4::text = 'incoming')
+      and ($5::bigint is null or amount >= $5::bigint)
+      and ($6::bigint is null or amount <= $6::bigint)
+      and id < $7
+    order by id desc
+    limit $8
+  )
+) as transfers
+order by id desc
+limit $8
+`
+
+type ListAccountTransfersByIDDescParams struct {
+	AccountID int64          `json:"account_id"`
+	FromTime  sql.NullTime   `json:"from_time"`
+	ToTime    sql.NullTime   `json:"to_time"`
+	Direction sql.NullString `json:"direction"`
+	MinAmount sql.NullInt64  `json:"min_amount"`
+	MaxAmount sql.NullInt64  `json:"max_amount"`
+	CursorID  int64          `json:"cursor_id"`
+	PageLimit int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountTransfersByIDDesc(ctx context.Context, arg ListAccountTransfersByIDDescParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfersByIDDesc,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.RateAt,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfers = `-- name: ListTransfers :many
+select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+from (
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where from_account_id = $1 and id > $3
+    order by id
+    limit $4
+  )
+  union all
+  (
+    select id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rate_at, reversal_of
+    from transfers
+    where to_account_id = $2 and from_account_id <> $1 and id > $3
+    order by id
+    limit $4
+  )
+) as transfers
 order by id
-limit $3
-offset $4
+limit $4
 `
 
 type ListTransfersParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
+	ID            int64 `json:"id"`
 	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
 	rows, err := q.db.QueryContext(ctx, listTransfers,
 		arg.FromAccountID,
 		arg.ToAccountID,
+		arg.ID,
 		arg.Limit,
-		arg.Offset,
 	)
 	if err != nil {
 		return nil, err
@@ -92,6 +552,10 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.RateAt,
+			&i.ReversalOf,
 		); err != nil {
 			return nil, err
 		}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"github/leoflalv/bank-api/util"
 	"testing"
 	"time"
@@ -10,10 +11,13 @@ import (
 )
 
 func createRandomTransfer(fromAccount Account, toAccount Account) Transfer {
+	amount := util.RandomNumber(1, 1000)
 	args := CreateTransferParams{
-		Amount:        util.RandomNumber(1, 1000),
+		Amount:        amount,
 		FromAccountID: fromAccount.ID,
 		ToAccountID:   toAccount.ID,
+		ToAmount:      amount,
+		ExchangeRate:  1,
 	}
 
 	entry, _ := testQueries.CreateTransfer(context.Background(), args)
@@ -25,10 +29,13 @@ func TestCreateTransaction(t *testing.T) {
 	fromAccount := createRandomAccount(t)
 	toAccount := createRandomAccount(t)
 
+	amount := util.RandomNumber(1, 1000)
 	args := CreateTransferParams{
-		Amount:        util.RandomNumber(1, 1000),
+		Amount:        amount,
 		FromAccountID: fromAccount.ID,
 		ToAccountID:   toAccount.ID,
+		ToAmount:      amount,
+		ExchangeRate:  1,
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), args)
@@ -38,6 +45,9 @@ func TestCreateTransaction(t *testing.T) {
 	require.Equal(t, args.Amount, transfer.Amount)
 	require.Equal(t, args.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, args.ToAccountID, transfer.ToAccountID)
+	require.Equal(t, args.ToAmount, transfer.ToAmount)
+	require.Equal(t, args.ExchangeRate, transfer.ExchangeRate)
+	require.False(t, transfer.RateAt.Valid)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -70,8 +80,8 @@ func TestListTransactions(t *testing.T) {
 
 	arg1 := ListTransfersParams{
 		FromAccountID: fromAccount.ID,
+		ID:            0,
 		Limit:         5,
-		Offset:        5,
 	}
 
 	transfers1, err := testQueries.ListTransfers(context.Background(), arg1)
@@ -85,8 +95,8 @@ func TestListTransactions(t *testing.T) {
 
 	arg2 := ListTransfersParams{
 		ToAccountID: toAccount.ID,
+		ID:          transfers1[len(transfers1)-1].ID,
 		Limit:       5,
-		Offset:      5,
 	}
 
 	transfers2, err := testQueries.ListTransfers(context.Background(), arg2)
@@ -99,3 +109,118 @@ func TestListTransactions(t *testing.T) {
 	}
 
 }
+
+func TestListAccountTransfersPage(t *testing.T) {
+	account := createRandomAccount(t)
+	otherAccount := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomTransfer(account, otherAccount)
+		createRandomTransfer(otherAccount, account)
+	}
+
+	arg := HistoryPageParams{
+		AccountID: account.ID,
+		Limit:     10,
+	}
+
+	transfers, err := testQueries.ListAccountTransfersPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 6)
+	for i := 1; i < len(transfers); i++ {
+		require.Less(t, transfers[i-1].ID, transfers[i].ID)
+	}
+
+	arg.Direction = sql.NullString{String: "outgoing", Valid: true}
+	arg.SortBy = SortByAmount
+	arg.SortDesc = true
+
+	transfers, err = testQueries.ListAccountTransfersPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+	for i, transfer := range transfers {
+		require.Equal(t, account.ID, transfer.FromAccountID)
+		if i > 0 {
+			require.LessOrEqual(t, transfer.Amount, transfers[i-1].Amount)
+		}
+	}
+
+	arg.Direction = sql.NullString{String: "incoming", Valid: true}
+	arg.MinAmount = sql.NullInt64{Int64: 1001, Valid: true}
+
+	transfers, err = testQueries.ListAccountTransfersPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestListAccountTransfersPageCursor(t *testing.T) {
+	account := createRandomAccount(t)
+	otherAccount := createRandomAccount(t)
+
+	var transfers1 []Transfer
+	for i := 0; i < 5; i++ {
+		transfers1 = append(transfers1, createRandomTransfer(account, otherAccount))
+	}
+
+	arg := HistoryPageParams{
+		AccountID: account.ID,
+		SortDesc:  true,
+		Limit:     3,
+	}
+
+	page1, err := testQueries.ListAccountTransfersPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page1, 3)
+
+	arg.After = &PageCursor{ID: page1[len(page1)-1].ID}
+	page2, err := testQueries.ListAccountTransfersPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page2, 2)
+
+	transfers := append(page1, page2...)
+	for i, transfer := range transfers {
+		require.Equal(t, transfers1[len(transfers1)-1-i].ID, transfer.ID)
+	}
+}
+
+func TestListAccountTransfersPageBothSides(t *testing.T) {
+	account := createRandomAccount(t)
+	otherAccount := createRandomAccount(t)
+
+	var created []Transfer
+	for i := 0; i < 3; i++ {
+		created = append(created, createRandomTransfer(account, otherAccount))
+		created = append(created, createRandomTransfer(otherAccount, account))
+	}
+	created = append(created, createRandomTransfer(account, account))
+
+	arg := HistoryPageParams{
+		AccountID: account.ID,
+		Limit:     4,
+	}
+
+	page1, err := testQueries.ListAccountTransfersPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page1, 4)
+
+	arg.After = &PageCursor{ID: page1[len(page1)-1].ID}
+	page2, err := testQueries.ListAccountTransfersPage(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page2, 3)
+
+	transfers := append(page1, page2...)
+	for i, transfer := range transfers {
+		require.Equal(t, created[i].ID, transfer.ID)
+	}
+
+	arg.After = nil
+	arg.Limit = 10
+	for _, direction := range []string{"incoming", "outgoing"} {
+		arg.Direction = sql.NullString{String: direction, Valid: true}
+
+		transfers, err = testQueries.ListAccountTransfersPage(context.Background(), arg)
+		require.NoError(t, err)
+		require.Len(t, transfers, 4)
+		require.Equal(t, created[len(created)-1].ID, transfers[len(transfers)-1].ID)
+	}
+}
@@ -0,0 +1,359 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// translatedQueries runs the queries outside of a transaction and passes
+// their errors through TranslateError, like execTx does for the ones run in a
+// transaction. The callers of the store only check the errors of this package.
+type translatedQueries struct {
+	q *Queries
+}
+
+func (t translatedQueries) AccountHasActivity(ctx context.Context, id int64) (bool, error) {
+	result, err := t.q.AccountHasActivity(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	result, err := t.q.AddAccountAvailableBalance(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	result, err := t.q.AddAccountBalance(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) BlockSession(ctx context.Context, arg BlockSessionParams) error {
+	return TranslateError(t.q.BlockSession(ctx, arg))
+}
+
+func (t translatedQueries) BlockUserSessions(ctx context.Context, username string) error {
+	return TranslateError(t.q.BlockUserSessions(ctx, username))
+}
+
+func (t translatedQueries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	result, err := t.q.CreateAccount(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error) {
+	result, err := t.q.CreateAdjustment(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	result, err := t.q.CreateEntry(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	result, err := t.q.CreateHold(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	result, err := t.q.CreateIdempotencyKey(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error) {
+	result, err := t.q.CreatePayment(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateReversalConsent(ctx context.Context, arg CreateReversalConsentParams) (ReversalConsent, error) {
+	result, err := t.q.CreateReversalConsent(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	result, err := t.q.CreateScheduledTransfer(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error) {
+	result, err := t.q.CreateScheduledTransferRun(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	result, err := t.q.CreateSession(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateSettlementAccount(ctx context.Context, currency string) error {
+	return TranslateError(t.q.CreateSettlementAccount(ctx, currency))
+}
+
+func (t translatedQueries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	result, err := t.q.CreateTransfer(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	result, err := t.q.CreateUser(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) DeleteAccount(ctx context.Context, id int64) error {
+	return TranslateError(t.q.DeleteAccount(ctx, id))
+}
+
+func (t translatedQueries) GetAccount(ctx context.Context, id int64) (Account, error) {
+	result, err := t.q.GetAccount(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	result, err := t.q.GetAccountForUpdate(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetAdjustment(ctx context.Context, id int64) (Adjustment, error) {
+	result, err := t.q.GetAdjustment(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetCurrency(ctx context.Context, code string) (Currency, error) {
+	result, err := t.q.GetCurrency(ctx, code)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	result, err := t.q.GetEntry(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetHold(ctx context.Context, id int64) (Hold, error) {
+	result, err := t.q.GetHold(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetHoldForUpdate(ctx context.Context, id int64) (Hold, error) {
+	result, err := t.q.GetHoldForUpdate(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	result, err := t.q.GetIdempotencyKey(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetPayment(ctx context.Context, id int64) (Payment, error) {
+	result, err := t.q.GetPayment(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetPaymentForUpdate(ctx context.Context, id int64) (Payment, error) {
+	result, err := t.q.GetPaymentForUpdate(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetReversalConsent(ctx context.Context, transferID int64) (ReversalConsent, error) {
+	result, err := t.q.GetReversalConsent(ctx, transferID)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetReversedAmounts(ctx context.Context, reversalOf sql.NullInt64) (GetReversedAmountsRow, error) {
+	result, err := t.q.GetReversedAmounts(ctx, reversalOf)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	result, err := t.q.GetScheduledTransfer(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	result, err := t.q.GetScheduledTransferForUpdate(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	result, err := t.q.GetSession(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetSettlementAccount(ctx context.Context, currency string) (Account, error) {
+	result, err := t.q.GetSettlementAccount(ctx, currency)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
+	result, err := t.q.GetTransfer(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	result, err := t.q.GetTransferForUpdate(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) GetUser(ctx context.Context, username string) (User, error) {
+	result, err := t.q.GetUser(ctx, username)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) IsTokenRevoked(ctx context.Context, arg IsTokenRevokedParams) (bool, error) {
+	result, err := t.q.IsTokenRevoked(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) IsTransferLinked(ctx context.Context, id int64) (bool, error) {
+	result, err := t.q.IsTransferLinked(ctx, id)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountEntriesByAmount(ctx context.Context, arg ListAccountEntriesByAmountParams) ([]Entry, error) {
+	result, err := t.q.ListAccountEntriesByAmount(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountEntriesByAmountDesc(ctx context.Context, arg ListAccountEntriesByAmountDescParams) ([]Entry, error) {
+	result, err := t.q.ListAccountEntriesByAmountDesc(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountEntriesByID(ctx context.Context, arg ListAccountEntriesByIDParams) ([]Entry, error) {
+	result, err := t.q.ListAccountEntriesByID(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountEntriesByIDDesc(ctx context.Context, arg ListAccountEntriesByIDDescParams) ([]Entry, error) {
+	result, err := t.q.ListAccountEntriesByIDDesc(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountEntriesPage(ctx context.Context, arg HistoryPageParams) ([]Entry, error) {
+	result, err := t.q.ListAccountEntriesPage(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountTransfersByAmount(ctx context.Context, arg ListAccountTransfersByAmountParams) ([]Transfer, error) {
+	result, err := t.q.ListAccountTransfersByAmount(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountTransfersByAmountDesc(ctx context.Context, arg ListAccountTransfersByAmountDescParams) ([]Transfer, error) {
+	result, err := t.q.ListAccountTransfersByAmountDesc(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountTransfersByID(ctx context.Context, arg ListAccountTransfersByIDParams) ([]Transfer, error) {
+	result, err := t.q.ListAccountTransfersByID(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountTransfersByIDDesc(ctx context.Context, arg ListAccountTransfersByIDDescParams) ([]Transfer, error) {
+	result, err := t.q.ListAccountTransfersByIDDesc(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccountTransfersPage(ctx context.Context, arg HistoryPageParams) ([]Transfer, error) {
+	result, err := t.q.ListAccountTransfersPage(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	result, err := t.q.ListAccounts(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListAdjustments(ctx context.Context, arg ListAdjustmentsParams) ([]Adjustment, error) {
+	result, err := t.q.ListAdjustments(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListBalanceDiscrepancies(ctx context.Context) ([]ListBalanceDiscrepanciesRow, error) {
+	result, err := t.q.ListBalanceDiscrepancies(ctx)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	result, err := t.q.ListCurrencies(ctx)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListDueScheduledTransfersForUpdate(ctx context.Context, arg ListDueScheduledTransfersForUpdateParams) ([]ScheduledTransfer, error) {
+	result, err := t.q.ListDueScheduledTransfersForUpdate(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	result, err := t.q.ListEntries(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListExpiredHoldsForUpdate(ctx context.Context, arg ListExpiredHoldsForUpdateParams) ([]Hold, error) {
+	result, err := t.q.ListExpiredHoldsForUpdate(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListPendingPayments(ctx context.Context, arg ListPendingPaymentsParams) ([]Payment, error) {
+	result, err := t.q.ListPendingPayments(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error) {
+	result, err := t.q.ListScheduledTransferRuns(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error) {
+	result, err := t.q.ListScheduledTransfers(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	result, err := t.q.ListTransfers(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) ListUnmatchedTransfers(ctx context.Context) ([]ListUnmatchedTransfersRow, error) {
+	result, err := t.q.ListUnmatchedTransfers(ctx)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) RevokeToken(ctx context.Context, arg RevokeTokenParams) error {
+	return TranslateError(t.q.RevokeToken(ctx, arg))
+}
+
+func (t translatedQueries) RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error {
+	return TranslateError(t.q.RevokeUserTokens(ctx, arg))
+}
+
+func (t translatedQueries) SumAccountEntriesSince(ctx context.Context, arg SumAccountEntriesSinceParams) (int64, error) {
+	result, err := t.q.SumAccountEntriesSince(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	result, err := t.q.UpdateAccountOverdraftLimit(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error) {
+	result, err := t.q.UpdateHold(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) UpdatePayment(ctx context.Context, arg UpdatePaymentParams) (Payment, error) {
+	result, err := t.q.UpdatePayment(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	result, err := t.q.UpdateScheduledTransfer(ctx, arg)
+	return result, TranslateError(err)
+}
+
+func (t translatedQueries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	result, err := t.q.UpdateUserRole(ctx, arg)
+	return result, TranslateError(err)
+}
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -14,7 +15,7 @@ insert into users (
   username, hashed_password, full_name, email
 ) values ( 
   $1, $2, $3, $4
-) returning username, hashed_password, full_name, email, password_changed_at, created_at
+) returning username, hashed_password, full_name, email, password_changed_at, created_at, tokens_valid_after, role
 `
 
 type CreateUserParams struct {
@@ -39,12 +40,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.TokensValidAfter,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-select username, hashed_password, full_name, email, password_changed_at, created_at
+select username, hashed_password, full_name, email, password_changed_at, created_at, tokens_valid_after, role
 from users
 where username = $1
 limit 1
@@ -60,6 +63,52 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.TokensValidAfter,
+		&i.Role,
+	)
+	return i, err
+}
+
+const revokeUserTokens = `-- name: RevokeUserTokens :exec
+update users
+set tokens_valid_after = $1
+where username = $2
+`
+
+type RevokeUserTokensParams struct {
+	ValidAfter time.Time `json:"valid_after"`
+	Username   string    `json:"username"`
+}
+
+func (q *Queries) RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error {
+	_, err := q.db.ExecContext(ctx, revokeUserTokens, arg.ValidAfter, arg.Username)
+	return err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+update users
+set role = $2
+where username = $1
+returning username, hashed_password, full_name, email, password_changed_at, created_at, tokens_valid_after, role
+`
+
+type UpdateUserRoleParams struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Username, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TokensValidAfter,
+		&i.Role,
 	)
 	return i, err
 }
@@ -0,0 +1,25 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRateNotFound is returned when the provider has no rate between the two
+// currencies.
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Rate is the amount of To currency bought by one unit of From currency, as
+// quoted at At.
+type Rate struct {
+	From  string    `json:"from"`
+	To    string    `json:"to"`
+	Value float64   `json:"value"`
+	At    time.Time `json:"at"`
+}
+
+// ExchangeRateProvider quotes exchange rates between currencies.
+type ExchangeRateProvider interface {
+	Rate(ctx context.Context, from string, to string) (Rate, error)
+}
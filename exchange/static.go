@@ -0,0 +1,84 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// StaticProvider quotes rates from a fixed table of rates against a base
+// currency, cross rates are derived through the base.
+type StaticProvider struct {
+	base      string
+	rates     map[string]float64
+	updatedAt time.Time
+}
+
+// NewStaticProvider creates a provider where one unit of base buys rates[c]
+// units of currency c.
+func NewStaticProvider(base string, rates map[string]float64, updatedAt time.Time) *StaticProvider {
+	table := make(map[string]float64, len(rates)+1)
+	for currency, rate := range rates {
+		table[currency] = rate
+	}
+	table[base] = 1
+
+	return &StaticProvider{
+		base:      base,
+		rates:     table,
+		updatedAt: updatedAt,
+	}
+}
+
+type ratesFile struct {
+	Base      string             `json:"base"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+// NewFileProvider creates a static provider from a JSON file holding the base
+// currency, the time the rates were taken and the rates against the base.
+func NewFileProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read exchange rates: %w", err)
+	}
+
+	var file ratesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("cannot parse exchange rates: %w", err)
+	}
+
+	if file.Base == "" {
+		return nil, fmt.Errorf("exchange rates file %s has no base currency", path)
+	}
+
+	for currency, rate := range file.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate for %s: %v", currency, rate)
+		}
+	}
+
+	return NewStaticProvider(file.Base, file.Rates, file.UpdatedAt), nil
+}
+
+func (provider *StaticProvider) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	fromRate, ok := provider.rates[from]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s to %s", ErrRateNotFound, from, to)
+	}
+
+	toRate, ok := provider.rates[to]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s to %s", ErrRateNotFound, from, to)
+	}
+
+	return Rate{
+		From:  from,
+		To:    to,
+		Value: toRate / fromRate,
+		At:    provider.updatedAt,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package exchange
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	updatedAt := time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)
+	provider := NewStaticProvider("USD", map[string]float64{"EUR": 0.5, "CAD": 1.25}, updatedAt)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "USD", rate.From)
+	require.Equal(t, "EUR", rate.To)
+	require.Equal(t, 0.5, rate.Value)
+	require.Equal(t, updatedAt, rate.At)
+
+	rate, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, 2.0, rate.Value)
+
+	rate, err = provider.Rate(context.Background(), "EUR", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, 2.5, rate.Value)
+
+	rate, err = provider.Rate(context.Background(), "CAD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, 1.0, rate.Value)
+
+	_, err = provider.Rate(context.Background(), "USD", "GBP")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{
+		"base": "EUR",
+		"updated_at": "2024-02-26T00:00:00Z",
+		"rates": {"USD": 1.08}
+	}`), 0o600)
+	require.NoError(t, err)
+
+	provider, err := NewFileProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, 1.08, rate.Value)
+	require.Equal(t, time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC), rate.At)
+
+	err = os.WriteFile(path, []byte(`{"base": "EUR", "rates": {"USD": 0}}`), 0o600)
+	require.NoError(t, err)
+
+	_, err = NewFileProvider(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,164 @@
+// Package logging sets up the structured logger of the server. Records
+// logged with a context carry the id of the request being served, and
+// passwords and tokens are redacted wherever they appear in the attributes.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats of the log output.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Redacted replaces the value of sensitive attributes.
+const Redacted = "[REDACTED]"
+
+// RequestIDKey is the attribute holding the request id.
+const RequestIDKey = "request_id"
+
+// sensitiveKeys are the attribute and field names whose value is never
+// logged, compared in lower case.
+var sensitiveKeys = map[string]bool{
+	"password":        true,
+	"hashed_password": true,
+	"hashedpassword":  true,
+	"token":           true,
+	"access_token":    true,
+	"refresh_token":   true,
+	"authorization":   true,
+	"secret":          true,
+}
+
+// New returns a logger writing to w at level, one of debug, info, warn and
+// error, in format. Empty level and format default to info and JSON.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q", level)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redactAttr}
+
+	var handler slog.Handler
+	switch format {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request id, logged with
+// every record logged with the context.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestID returns the request id carried by ctx, if any.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request id of the context to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String(RequestIDKey, requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// redactAttr hides the value of sensitive attributes, and of the sensitive
+// fields of structs and maps logged as a whole.
+func redactAttr(_ []string, attr slog.Attr) slog.Attr {
+	if isSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	if attr.Value.Kind() == slog.KindAny {
+		if _, isErr := attr.Value.Any().(error); !isErr {
+			attr.Value = slog.AnyValue(Redact(attr.Value.Any()))
+		}
+	}
+	return attr
+}
+
+func isSensitive(key string) bool {
+	return sensitiveKeys[strings.ToLower(key)]
+}
+
+// Redact returns payload with the sensitive fields replaced, as found in
+// its JSON encoding. Payloads that aren't JSON objects or arrays, or hold no
+// sensitive field, are returned as they are.
+func Redact(payload any) any {
+	data, err := json.Marshal(payload)
+	if err != nil || len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return payload
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return payload
+	}
+
+	if !redactValue(decoded) {
+		return payload
+	}
+	return decoded
+}
+
+// redactValue replaces the sensitive fields of v in place and reports
+// whether it found any.
+func redactValue(v any) bool {
+	found := false
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitive(key) {
+				v[key] = Redacted
+				found = true
+				continue
+			}
+			if redactValue(field) {
+				found = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if redactValue(item) {
+				found = true
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type loginPayload struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type sessionPayload struct {
+	User         loginPayload `json:"user"`
+	RefreshToken string       `json:"refresh_token"`
+	Roles        []string     `json:"roles"`
+}
+
+func newTestLogger(t *testing.T, level string) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, level, FormatJSON)
+	require.NoError(t, err)
+	return logger, &buf
+}
+
+func lastRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(lines[len(lines)-1], &record))
+	return record
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "loud", FormatJSON)
+	require.Error(t, err)
+
+	_, err = New(&bytes.Buffer{}, "debug", "xml")
+	require.Error(t, err)
+
+	logger, err := New(&bytes.Buffer{}, "", "")
+	require.NoError(t, err)
+	require.True(t, logger.Enabled(context.Background(), slog.LevelInfo))
+	require.False(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	logger, buf := newTestLogger(t, "warn")
+	logger.Info("hidden")
+	require.Zero(t, buf.Len())
+	logger.Warn("shown")
+	require.Equal(t, "shown", lastRecord(t, buf)["msg"])
+
+	var text bytes.Buffer
+	logger, err = New(&text, "info", FormatText)
+	require.NoError(t, err)
+	logger.Info("hello", "password", "secret")
+	require.Contains(t, text.String(), "msg=hello password="+Redacted)
+}
+
+func TestRequestID(t *testing.T) {
+	logger, buf := newTestLogger(t, "info")
+
+	logger.InfoContext(context.Background(), "no request")
+	require.NotContains(t, lastRecord(t, buf), RequestIDKey)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	require.Equal(t, "req-1", RequestID(ctx))
+
+	logger.With("component", "test").WithGroup("details").InfoContext(ctx, "in request", "step", 1)
+	record := lastRecord(t, buf)
+	require.Equal(t, "req-1", record["details"].(map[string]any)[RequestIDKey])
+	require.Equal(t, "test", record["component"])
+}
+
+func TestRedaction(t *testing.T) {
+	logger, buf := newTestLogger(t, "info")
+
+	session := sessionPayload{
+		User:         loginPayload{Username: "alice", Password: "hunter22"},
+		RefreshToken: "v2.local.abc",
+		Roles:        []string{"depositor"},
+	}
+	logger.Info("payloads",
+		"password", "hunter22",
+		"Authorization", "Bearer v2.local.abc",
+		slog.Group("user", "hashed_password", "$2a$10$xyz", "username", "alice"),
+		"session", session,
+		"sessions", []sessionPayload{session},
+		"body", map[string]any{"access_token": "v2.local.def", "amount": 10},
+		"error", errors.New("token expired"),
+		"count", 3,
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"hunter22", "v2.local.abc", "v2.local.def", "$2a$10$xyz"} {
+		require.NotContains(t, out, secret)
+	}
+
+	record := lastRecord(t, buf)
+	require.Equal(t, Redacted, record["password"])
+	require.Equal(t, Redacted, record["Authorization"])
+	require.Equal(t, map[string]any{"hashed_password": Redacted, "username": "alice"}, record["user"])
+	require.Equal(t, map[string]any{
+		"user":          map[string]any{"username": "alice", "password": Redacted},
+		"refresh_token": Redacted,
+		"roles":         []any{"depositor"},
+	}, record["session"])
+	require.Equal(t, map[string]any{"access_token": Redacted, "amount": 10.0}, record["body"])
+	require.Equal(t, "token expired", record["error"])
+	require.Equal(t, 3.0, record["count"])
+
+	// Payloads without sensitive fields are logged as they are.
+	account := struct {
+		Owner   string `json:"owner"`
+		Balance int64  `json:"balance"`
+	}{"bob", 100}
+	require.Equal(t, account, Redact(account))
+}
@@ -1,11 +1,20 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"encoding/json"
 	"github/leoflalv/bank-api/api"
 	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/logging"
+	"github/leoflalv/bank-api/payment"
+	"github/leoflalv/bank-api/tracing"
 	"github/leoflalv/bank-api/util"
-	"log"
+	"github/leoflalv/bank-api/worker"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	_ "github.com/lib/pq"
 )
@@ -13,24 +22,164 @@ import (
 func main() {
 	config, err := util.LoadConfig(".", true)
 	if err != nil {
-		log.Fatal("cannot load config:", err)
+		fatal("cannot load config", err)
 	}
 
-	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	// Logs go to stderr, stdout is left to the output of commands.
+	logger, err := logging.New(os.Stderr, config.LogLevel, config.LogFormat)
 	if err != nil {
-		log.Fatal("cannot connect to db:", err)
+		fatal("cannot create logger", err)
 	}
+	slog.SetDefault(logger)
 
-	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    config.TracingExporter,
+		Endpoint:    config.TracingOTLPEndpoint,
+		Insecure:    config.TracingOTLPInsecure,
+		SampleRatio: config.TracingSampleRatio,
+		Writer:      os.Stderr,
+	})
 	if err != nil {
-		log.Fatal("cannot create server:", err)
+		fatal("cannot set up tracing", err)
+	}
+
+	conn, err := db.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		fatal("cannot connect to db", err)
+	}
+
+	txIsolation, err := db.ParseIsolationLevel(config.TxIsolation)
+	if err != nil {
+		fatal("cannot parse transaction isolation", err)
+	}
+
+	store := db.NewStoreWithOptions(conn, db.StoreOptions{
+		Retry: db.RetryPolicy{
+			MaxRetries: config.TxMaxRetries,
+			BaseDelay:  config.TxRetryBaseDelay,
+			MaxDelay:   config.TxRetryMaxDelay,
+		},
+		TransferIsolation: txIsolation,
+	})
+
+	err = db.WaitForDB(context.Background(), store, db.RetryPolicy{
+		MaxRetries: config.DBConnectRetries,
+		BaseDelay:  config.DBConnectBaseDelay,
+		MaxDelay:   config.DBConnectMaxDelay,
+	})
+	if err != nil {
+		fatal("cannot reach db", err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		balanced := reconcile(context.Background(), store)
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("cannot flush traces", "error", err)
+		}
+		if !balanced {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The rail is shared by the requests making payments and the worker
+	// settling the ones left pending.
+	rail, err := payment.NewRail(config.PaymentRail, config.AllowFakeRail, config.FakeRailLimit)
+	if err != nil {
+		fatal("cannot create payment rail", err)
+	}
+
+	server, err := api.NewServer(config, store, rail)
+	if err != nil {
+		fatal("cannot create server", err)
 	}
 
 	api.SetupRoutes(server)
+	db.RegisterMetrics(store)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The workers get their own context so they keep running while the
+	// requests in flight are drained, they may be needed to finish them.
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	runWorker := func(run func(context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workersCtx)
+		}()
+	}
+
+	runWorker(server.RefreshCurrencies)
+
+	scheduledTransfers := worker.NewScheduledTransferRunner(store, config.SchedulerBatchSize)
+	runWorker(func(ctx context.Context) { scheduledTransfers.Run(ctx, config.SchedulerInterval) })
+
+	holds := worker.NewHoldReleaser(store, config.SchedulerBatchSize)
+	runWorker(func(ctx context.Context) { holds.Run(ctx, config.HoldReleaseInterval) })
+
+	payments := worker.NewPaymentSettler(store, rail, config.SchedulerBatchSize, config.PendingPaymentMinAge, config.PendingPaymentTimeout)
+	runWorker(func(ctx context.Context) { payments.Run(ctx, config.PaymentSettleInterval) })
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start(config.ServerAddress)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			fatal("cannot start server", err)
+		}
+	case <-ctx.Done():
+		stop()
+		slog.Info("shutting down, draining requests in flight")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		err := server.Shutdown(shutdownCtx)
+		cancel()
+		if err != nil {
+			slog.Error("cannot drain requests in flight", "error", err)
+		}
+		<-serverErr
+	}
+
+	stopWorkers()
+	workers.Wait()
 
-	err = server.Start(config.ServerAddress)
+	flushCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("cannot flush traces", "error", err)
+	}
+	cancel()
+
+	if err := conn.Close(); err != nil {
+		slog.Error("cannot close db", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// reconcile checks the balances and transfers against the entries, writes the
+// discrepancies found as JSON to stdout and reports whether there were none.
+func reconcile(ctx context.Context, store db.Store) bool {
+	result, err := store.Reconcile(ctx)
 	if err != nil {
-		log.Fatal("cannot start server:", err)
+		fatal("cannot reconcile ledger", err)
 	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fatal("cannot write reconciliation", err)
+	}
+
+	return result.Balanced()
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+)
+
+// FakeRail settles every payment instantly, for local development and tests.
+// Payments above Limit fail, no limit applies when it is zero.
+type FakeRail struct {
+	Limit int64
+}
+
+func NewFakeRail(limit int64) *FakeRail {
+	return &FakeRail{Limit: limit}
+}
+
+func (rail *FakeRail) Collect(ctx context.Context, req Request) (Result, error) {
+	return rail.settle(req), nil
+}
+
+func (rail *FakeRail) Payout(ctx context.Context, req Request) (Result, error) {
+	return rail.settle(req), nil
+}
+
+// Status answers like the payment was just sent, since the fake rail settles
+// every payment on the spot.
+func (rail *FakeRail) Status(ctx context.Context, req Request) (Result, error) {
+	return rail.settle(req), nil
+}
+
+func (rail *FakeRail) settle(req Request) Result {
+	result := Result{
+		Reference: fmt.Sprintf("fake-%d", req.PaymentID),
+		Status:    StatusCompleted,
+	}
+
+	if rail.Limit > 0 && req.Amount > rail.Limit {
+		result.Status = StatusFailed
+		result.FailureReason = fmt.Sprintf("amount above the rail limit of %d", rail.Limit)
+	}
+
+	return result
+}
@@ -0,0 +1,48 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeRail(t *testing.T) {
+	rail := NewFakeRail(100)
+
+	result, err := rail.Collect(context.Background(), Request{PaymentID: 1, Amount: 100, Currency: "USD"})
+	require.NoError(t, err)
+	require.Equal(t, StatusCompleted, result.Status)
+	require.Equal(t, "fake-1", result.Reference)
+	require.Empty(t, result.FailureReason)
+
+	result, err = rail.Payout(context.Background(), Request{PaymentID: 2, Amount: 101, Currency: "USD"})
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, result.Status)
+	require.Equal(t, "fake-2", result.Reference)
+	require.NotEmpty(t, result.FailureReason)
+
+	result, err = rail.Status(context.Background(), Request{PaymentID: 2, Amount: 101, Currency: "USD"})
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, result.Status)
+	require.Equal(t, "fake-2", result.Reference)
+
+	result, err = NewFakeRail(0).Payout(context.Background(), Request{PaymentID: 3, Amount: 1_000_000})
+	require.NoError(t, err)
+	require.Equal(t, StatusCompleted, result.Status)
+}
+
+func TestNewRail(t *testing.T) {
+	rail, err := NewRail(RailFake, true, 100)
+	require.NoError(t, err)
+	require.Equal(t, NewFakeRail(100), rail)
+
+	_, err = NewRail(RailFake, false, 100)
+	require.ErrorIs(t, err, ErrFakeRailNotAllowed)
+
+	_, err = NewRail("", true, 0)
+	require.Error(t, err)
+
+	_, err = NewRail("wire", true, 0)
+	require.Error(t, err)
+}
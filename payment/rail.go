@@ -0,0 +1,66 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Outcomes of a payment reported by a rail.
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// RailFake names the FakeRail in the configuration.
+const RailFake = "fake"
+
+// ErrFakeRailNotAllowed is returned when the fake rail is configured without
+// being allowed, it would let anyone deposit money that doesn't exist.
+var ErrFakeRailNotAllowed = errors.New("the fake payment rail is only allowed in development and tests")
+
+// Request describes a payment sent to a rail. PaymentID is unique per
+// payment, rails use it to deduplicate retries.
+type Request struct {
+	PaymentID int64  `json:"payment_id"`
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+}
+
+// Result is the outcome of a payment on the rail. A pending payment is settled
+// later, once the rail reports its final status.
+type Result struct {
+	Reference     string `json:"reference"`
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason"`
+}
+
+// PaymentRail moves money between the bank and the outside world.
+type PaymentRail interface {
+	// Collect pulls the funds of a deposit into the bank.
+	Collect(ctx context.Context, req Request) (Result, error)
+	// Payout sends the funds of a withdrawal out of the bank.
+	Payout(ctx context.Context, req Request) (Result, error)
+	// Status returns the outcome of the payment sent with req, looked up by
+	// its PaymentID, without sending it again.
+	Status(ctx context.Context, req Request) (Result, error)
+}
+
+// NewRail returns the rail configured by name. The fake rail is only returned
+// when allowFake is set, and there is no default: a server without a rail
+// must not start.
+func NewRail(name string, allowFake bool, fakeLimit int64) (PaymentRail, error) {
+	switch name {
+	case "":
+		return nil, errors.New("no payment rail configured")
+	case RailFake:
+		if !allowFake {
+			return nil, ErrFakeRailNotAllowed
+		}
+		return NewFakeRail(fakeLimit), nil
+	default:
+		return nil, fmt.Errorf("unknown payment rail %q", name)
+	}
+}
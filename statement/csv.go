@@ -0,0 +1,60 @@
+package statement
+
+import (
+	"encoding/csv"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/util"
+	"io"
+	"strconv"
+)
+
+// csvWriter writes a row per entry between an opening and a closing balance
+// row. Amounts are decimals in the account currency.
+type csvWriter struct {
+	w        *csv.Writer
+	exponent int
+}
+
+func newCSVWriter(w io.Writer, exponent int) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w), exponent: exponent}
+}
+
+func (writer *csvWriter) Open(statement db.AccountStatement) error {
+	if err := writer.w.Write([]string{"type", "entry_id", "date", "amount", "balance"}); err != nil {
+		return err
+	}
+
+	return writer.w.Write([]string{
+		"opening",
+		"",
+		statement.From.Format(timeFormat),
+		"",
+		util.FormatAmount(statement.OpeningBalance, writer.exponent),
+	})
+}
+
+func (writer *csvWriter) Entry(entry db.StatementEntry) error {
+	return writer.w.Write([]string{
+		"entry",
+		strconv.FormatInt(entry.ID, 10),
+		entry.CreatedAt.Format(timeFormat),
+		util.FormatAmount(entry.Amount, writer.exponent),
+		util.FormatAmount(entry.Balance, writer.exponent),
+	})
+}
+
+func (writer *csvWriter) Close(statement db.AccountStatement) error {
+	err := writer.w.Write([]string{
+		"closing",
+		"",
+		statement.To.Format(timeFormat),
+		"",
+		util.FormatAmount(statement.ClosingBalance, writer.exponent),
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.w.Flush()
+	return writer.w.Error()
+}
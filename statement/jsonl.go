@@ -0,0 +1,63 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"io"
+)
+
+// jsonlLine is a line of a JSON Lines statement. Amounts are in minor units,
+// like everywhere else in the API.
+type jsonlLine struct {
+	Type      string `json:"type"`
+	AccountID int64  `json:"account_id,omitempty"`
+	Currency  string `json:"currency,omitempty"`
+	EntryID   int64  `json:"entry_id,omitempty"`
+	Date      string `json:"date"`
+	Amount    *int64 `json:"amount,omitempty"`
+	Balance   int64  `json:"balance"`
+}
+
+type jsonlWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	buf := bufio.NewWriter(w)
+	return &jsonlWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (writer *jsonlWriter) Open(statement db.AccountStatement) error {
+	return writer.enc.Encode(jsonlLine{
+		Type:      "opening",
+		AccountID: statement.Account.ID,
+		Currency:  statement.Account.Currency,
+		Date:      statement.From.Format(timeFormat),
+		Balance:   statement.OpeningBalance,
+	})
+}
+
+func (writer *jsonlWriter) Entry(entry db.StatementEntry) error {
+	return writer.enc.Encode(jsonlLine{
+		Type:    "entry",
+		EntryID: entry.ID,
+		Date:    entry.CreatedAt.Format(timeFormat),
+		Amount:  &entry.Amount,
+		Balance: entry.Balance,
+	})
+}
+
+func (writer *jsonlWriter) Close(statement db.AccountStatement) error {
+	err := writer.enc.Encode(jsonlLine{
+		Type:    "closing",
+		Date:    statement.To.Format(timeFormat),
+		Balance: statement.ClosingBalance,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.buf.Flush()
+}
@@ -0,0 +1,110 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/util"
+	"io"
+	"time"
+)
+
+// ofxBankID identifies the bank in the account of an OFX statement.
+const ofxBankID = "BANKAPI"
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// ofxWriter writes an OFX 2.2 bank statement. OFX has no opening or running
+// balance, the closing balance is written as the ledger balance.
+type ofxWriter struct {
+	buf      *bufio.Writer
+	exponent int
+	now      func() time.Time
+}
+
+func newOFXWriter(w io.Writer, exponent int) *ofxWriter {
+	return &ofxWriter{buf: bufio.NewWriter(w), exponent: exponent, now: time.Now}
+}
+
+func (writer *ofxWriter) Open(statement db.AccountStatement) error {
+	_, err := fmt.Fprintf(writer.buf, ofxHeader+`<OFX>
+<SIGNONMSGSRSV1>
+<SONRS>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<DTSERVER>%s</DTSERVER>
+<LANGUAGE>ENG</LANGUAGE>
+</SONRS>
+</SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>0</TRNUID>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS>
+<CURDEF>%s</CURDEF>
+<BANKACCTFROM>
+<BANKID>%s</BANKID>
+<ACCTID>%d</ACCTID>
+<ACCTTYPE>CHECKING</ACCTTYPE>
+</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>%s</DTSTART>
+<DTEND>%s</DTEND>
+`,
+		ofxTime(writer.now()),
+		statement.Account.Currency,
+		ofxBankID,
+		statement.Account.ID,
+		ofxTime(statement.From),
+		ofxTime(statement.To),
+	)
+	return err
+}
+
+func (writer *ofxWriter) Entry(entry db.StatementEntry) error {
+	trnType := "CREDIT"
+	if entry.Amount < 0 {
+		trnType = "DEBIT"
+	}
+
+	_, err := fmt.Fprintf(writer.buf, `<STMTTRN>
+<TRNTYPE>%s</TRNTYPE>
+<DTPOSTED>%s</DTPOSTED>
+<TRNAMT>%s</TRNAMT>
+<FITID>%d</FITID>
+</STMTTRN>
+`,
+		trnType,
+		ofxTime(entry.CreatedAt),
+		util.FormatAmount(entry.Amount, writer.exponent),
+		entry.ID,
+	)
+	return err
+}
+
+func (writer *ofxWriter) Close(statement db.AccountStatement) error {
+	_, err := fmt.Fprintf(writer.buf, `</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>%s</BALAMT>
+<DTASOF>%s</DTASOF>
+</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`,
+		util.FormatAmount(statement.ClosingBalance, writer.exponent),
+		ofxTime(statement.To),
+	)
+	if err != nil {
+		return err
+	}
+
+	return writer.buf.Flush()
+}
+
+// ofxTime writes t in UTC in the OFX datetime format.
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
@@ -0,0 +1,46 @@
+// Package statement writes account statements in the formats they can be
+// downloaded in.
+package statement
+
+import (
+	"fmt"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"io"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatOFX   = "ofx"
+)
+
+// timeFormat is how dates are written in the CSV and JSON Lines statements.
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// ContentType returns the media type of a statement format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/jsonl; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	}
+	return "application/octet-stream"
+}
+
+// NewWriter returns a writer of statements in format to w. Amounts are in
+// minor units of a currency with exponent digits after the separator. The
+// output is buffered and flushed when the statement is closed.
+func NewWriter(format string, w io.Writer, exponent int) (db.StatementWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, exponent), nil
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatOFX:
+		return newOFXWriter(w, exponent), nil
+	}
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
@@ -0,0 +1,116 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/json"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	statementFrom = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	statementTo   = time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func testStatement() (db.AccountStatement, []db.StatementEntry) {
+	statement := db.AccountStatement{
+		Account:        db.Account{ID: 7, Owner: "owner", Currency: "USD"},
+		From:           statementFrom,
+		To:             statementTo,
+		OpeningBalance: 1000,
+		ClosingBalance: 875,
+	}
+
+	entries := []db.StatementEntry{
+		{
+			Entry:   db.Entry{ID: 1, AccountID: 7, Amount: -250, CreatedAt: time.Date(2024, 3, 2, 10, 30, 0, 0, time.UTC)},
+			Balance: 750,
+		},
+		{
+			Entry:   db.Entry{ID: 2, AccountID: 7, Amount: 125, CreatedAt: time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)},
+			Balance: 875,
+		},
+	}
+
+	return statement, entries
+}
+
+func writeStatement(t *testing.T, format string) string {
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, 2)
+	require.NoError(t, err)
+
+	statement, entries := testStatement()
+	require.NoError(t, w.Open(statement))
+	for _, entry := range entries {
+		require.NoError(t, w.Entry(entry))
+	}
+	require.NoError(t, w.Close(statement))
+
+	return buf.String()
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := writeStatement(t, FormatCSV)
+
+	require.Equal(t, strings.Join([]string{
+		"type,entry_id,date,amount,balance",
+		"opening,,2024-03-01T00:00:00.000Z,,10.00",
+		"entry,1,2024-03-02T10:30:00.000Z,-2.50,7.50",
+		"entry,2,2024-03-05T08:00:00.000Z,1.25,8.75",
+		"closing,,2024-04-01T00:00:00.000Z,,8.75",
+		"",
+	}, "\n"), out)
+}
+
+func TestJSONLWriter(t *testing.T) {
+	out := writeStatement(t, FormatJSONL)
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	require.Len(t, lines, 4)
+
+	var opening jsonlLine
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &opening))
+	require.Equal(t, "opening", opening.Type)
+	require.Equal(t, int64(7), opening.AccountID)
+	require.Equal(t, "USD", opening.Currency)
+	require.Equal(t, int64(1000), opening.Balance)
+	require.Nil(t, opening.Amount)
+
+	var entry jsonlLine
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	require.Equal(t, "entry", entry.Type)
+	require.Equal(t, int64(1), entry.EntryID)
+	require.NotNil(t, entry.Amount)
+	require.Equal(t, int64(-250), *entry.Amount)
+	require.Equal(t, int64(750), entry.Balance)
+
+	var closing jsonlLine
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &closing))
+	require.Equal(t, "closing", closing.Type)
+	require.Equal(t, "2024-04-01T00:00:00.000Z", closing.Date)
+	require.Equal(t, int64(875), closing.Balance)
+}
+
+func TestOFXWriter(t *testing.T) {
+	out := writeStatement(t, FormatOFX)
+
+	require.True(t, strings.HasPrefix(out, ofxHeader))
+	require.Contains(t, out, "<CURDEF>USD</CURDEF>")
+	require.Contains(t, out, "<ACCTID>7</ACCTID>")
+	require.Contains(t, out, "<DTSTART>20240301000000.000[0:GMT]</DTSTART>")
+	require.Contains(t, out, "<TRNTYPE>DEBIT</TRNTYPE>\n<DTPOSTED>20240302103000.000[0:GMT]</DTPOSTED>\n<TRNAMT>-2.50</TRNAMT>\n<FITID>1</FITID>")
+	require.Contains(t, out, "<TRNTYPE>CREDIT</TRNTYPE>\n<DTPOSTED>20240305080000.000[0:GMT]</DTPOSTED>\n<TRNAMT>1.25</TRNAMT>\n<FITID>2</FITID>")
+	require.Contains(t, out, "<LEDGERBAL>\n<BALAMT>8.75</BALAMT>\n<DTASOF>20240401000000.000[0:GMT]</DTASOF>")
+	require.True(t, strings.HasSuffix(out, "</OFX>\n"))
+}
+
+func TestNewWriterUnsupportedFormat(t *testing.T) {
+	w, err := NewWriter("xlsx", &bytes.Buffer{}, 2)
+	require.Error(t, err)
+	require.Nil(t, w)
+}
@@ -22,18 +22,19 @@ func NewJwtManager(secretKey string) (Manager, error) {
 	return &JwtManager{secretKey}, nil
 }
 
-func (manager *JwtManager) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (manager *JwtManager) CreateToken(username string, role string, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, tokenType, duration)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	return jwtToken.SignedString([]byte(manager.secretKey))
+	token, err := jwtToken.SignedString([]byte(manager.secretKey))
+	return token, payload, err
 }
 
 // Check if the token is valid
-func (manager *JwtManager) VerifyToken(token string) (*Payload, error) {
+func (manager *JwtManager) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
 	keyFunc := func(token *jwt.Token) (any, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
@@ -52,7 +53,7 @@ func (manager *JwtManager) VerifyToken(token string) (*Payload, error) {
 	}
 
 	payload, ok := jwtToken.Claims.(*Payload)
-	if !ok {
+	if !ok || payload.Type != tokenType {
 		return nil, ErrInvalidToken
 	}
 
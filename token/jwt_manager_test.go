@@ -14,42 +14,63 @@ func TestJwt(t *testing.T) {
 	require.NoError(t, err)
 
 	username := util.RandomString(10)
+	role := util.DepositorRole
 	duration := time.Minute
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, err := manager.CreateToken(username, duration)
+	token, payload, err := manager.CreateToken(username, role, AccessToken, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
 
-	payload, err := manager.VerifyToken(token)
+	payload, err = manager.VerifyToken(token, AccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
 	require.NotZero(t, payload.Id)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, AccessToken, payload.Type)
+	require.Equal(t, role, payload.Role)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
 
+func TestJwtWrongType(t *testing.T) {
+	manager, err := NewJwtManager(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := manager.CreateToken(util.RandomString(10), util.DepositorRole, RefreshToken, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := manager.VerifyToken(token, AccessToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+
+	payload, err = manager.VerifyToken(token, RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, RefreshToken, payload.Type)
+}
+
 func TestExpiredJwt(t *testing.T) {
 	manager, err := NewJwtManager(util.RandomString(32))
 	require.NoError(t, err)
 
 	username := util.RandomString(10)
 
-	token, err := manager.CreateToken(username, -time.Minute)
+	token, payload, err := manager.CreateToken(username, util.DepositorRole, AccessToken, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
 
-	payload, err := manager.VerifyToken(token)
+	payload, err = manager.VerifyToken(token, AccessToken)
 	require.Error(t, err)
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
 
 func TestInvalidJwtTokenAlgNone(t *testing.T) {
-	payload, err := NewPayload(util.RandomString(10), time.Minute)
+	payload, err := NewPayload(util.RandomString(10), util.DepositorRole, AccessToken, time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -58,7 +79,7 @@ func TestInvalidJwtTokenAlgNone(t *testing.T) {
 	require.NoError(t, err)
 
 	manager, err := NewJwtManager(util.RandomString(32))
-	payload, err = manager.VerifyToken(token)
+	payload, err = manager.VerifyToken(token, AccessToken)
 	require.Error(t, err)
 	require.EqualError(t, err, ErrInvalidToken.Error())
 	require.Nil(t, payload)
@@ -4,9 +4,9 @@ import "time"
 
 // Interface for managing tokens
 type Manager interface {
-	// Creates a new token for a specific username and duration
-	CreateToken(username string, duration time.Duration) (string, error)
+	// Creates a new token of the given type for a specific username, role and duration
+	CreateToken(username string, role string, tokenType TokenType, duration time.Duration) (string, *Payload, error)
 
-	// Check if the token is valid
-	VerifyToken(token string) (*Payload, error)
+	// Check if the token is valid and of the given type
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
 }
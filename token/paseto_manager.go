@@ -26,17 +26,18 @@ func NewPasetoManager(symetricKey string) (Manager, error) {
 	return manager, nil
 }
 
-func (manager *PasetoManager) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (manager *PasetoManager) CreateToken(username string, role string, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, tokenType, duration)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return manager.paseto.Encrypt(manager.symmetricKey, payload, nil)
+	token, err := manager.paseto.Encrypt(manager.symmetricKey, payload, nil)
+	return token, payload, err
 }
 
 // Check if the token is valid
-func (manager *PasetoManager) VerifyToken(token string) (*Payload, error) {
+func (manager *PasetoManager) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
 	payload := &Payload{}
 
 	err := manager.paseto.Decrypt(token, manager.symmetricKey, payload, nil)
@@ -49,5 +50,9 @@ func (manager *PasetoManager) VerifyToken(token string) (*Payload, error) {
 		return nil, err
 	}
 
+	if payload.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+
 	return payload, nil
 }
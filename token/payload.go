@@ -12,14 +12,27 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// TokenType tells what a token can be used for, a token of one type is
+// rejected where the other is expected.
+type TokenType string
+
+const (
+	// AccessToken authenticates the requests made to the API.
+	AccessToken TokenType = "access"
+	// RefreshToken is only accepted to get new access tokens.
+	RefreshToken TokenType = "refresh"
+)
+
 type Payload struct {
 	Id        uuid.UUID `json:"id"`
+	Type      TokenType `json:"type"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -27,7 +40,9 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 
 	payload := &Payload{
 		Id:        tokenId,
+		Type:      tokenType,
 		Username:  username,
+		Role:      role,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -0,0 +1,89 @@
+// Package tracing sets up OpenTelemetry to export the spans of the server.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ServiceName identifies the spans of the server in the tracing backend.
+const ServiceName = "bank-api"
+
+// Exporters the spans can be sent to.
+const (
+	// ExporterNone drops the spans, the default.
+	ExporterNone = "none"
+	// ExporterStdout writes the spans as JSON, for local development.
+	ExporterStdout = "stdout"
+	// ExporterOTLP sends the spans to an OTLP collector over HTTP.
+	ExporterOTLP = "otlp"
+)
+
+// Config tells where the spans go.
+type Config struct {
+	Exporter string
+	// Endpoint is the host and port of the OTLP collector, the exporter
+	// defaults to localhost:4318 or OTEL_EXPORTER_OTLP_ENDPOINT.
+	Endpoint string
+	// Insecure sends the spans over plain HTTP.
+	Insecure bool
+	// SampleRatio is the fraction of traces started by the server that are
+	// kept, traces started upstream follow the decision of their parent.
+	SampleRatio float64
+	// Writer receives the spans of the stdout exporter.
+	Writer io.Writer
+}
+
+// Setup installs the global tracer provider and propagator. The returned
+// function flushes the spans not exported yet and stops the exporter, it
+// has to be called before the process exits.
+func Setup(ctx context.Context, config Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch config.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		opts := []stdouttrace.Option{stdouttrace.WithPrettyPrint()}
+		if config.Writer != nil {
+			opts = append(opts, stdouttrace.WithWriter(config.Writer))
+		}
+		exporter, err = stdouttrace.New(opts...)
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %s exporter: %w", config.Exporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
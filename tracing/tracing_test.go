@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(provider)
+
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+	require.Equal(t, provider, otel.GetTracerProvider())
+
+	_, err = Setup(context.Background(), Config{Exporter: "zipkin"})
+	require.Error(t, err)
+
+	shutdown, err = Setup(context.Background(), Config{Exporter: ExporterOTLP, Endpoint: "localhost:4318", Insecure: true, SampleRatio: 1})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetupStdout(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(provider)
+
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterStdout, SampleRatio: 1, Writer: &buf})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "GET /currencies")
+	span.End()
+
+	_, other := otel.Tracer("test").Start(context.Background(), "other")
+	require.True(t, other.SpanContext().IsSampled())
+	other.End()
+
+	require.NoError(t, shutdown(context.Background()))
+	require.Contains(t, buf.String(), `"Name": "GET /currencies"`)
+	require.Contains(t, buf.String(), ServiceName)
+}
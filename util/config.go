@@ -8,11 +8,44 @@ import (
 
 // All the app configurations readed by viper from env variables
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver                string        `mapstructure:"DB_DRIVER"`
+	DBSource                string        `mapstructure:"DB_SOURCE"`
+	ServerAddress           string        `mapstructure:"SERVER_ADDRESS"`
+	LogLevel                string        `mapstructure:"LOG_LEVEL"`
+	LogFormat               string        `mapstructure:"LOG_FORMAT"`
+	TracingExporter         string        `mapstructure:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint     string        `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure     bool          `mapstructure:"TRACING_OTLP_INSECURE"`
+	TracingSampleRatio      float64       `mapstructure:"TRACING_SAMPLE_RATIO"`
+	HTTPReadTimeout         time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPReadHeaderTimeout   time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	HTTPWriteTimeout        time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout         time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout         time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ReadinessTimeout        time.Duration `mapstructure:"READINESS_TIMEOUT"`
+	DBConnectRetries        int           `mapstructure:"DB_CONNECT_RETRIES"`
+	DBConnectBaseDelay      time.Duration `mapstructure:"DB_CONNECT_BASE_DELAY"`
+	DBConnectMaxDelay       time.Duration `mapstructure:"DB_CONNECT_MAX_DELAY"`
+	TokenSymmetricKey       string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration     time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration    time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	RevocationCacheTTL      time.Duration `mapstructure:"REVOCATION_CACHE_TTL"`
+	MaxPageSize             int32         `mapstructure:"MAX_PAGE_SIZE"`
+	ExchangeRatesFile       string        `mapstructure:"EXCHANGE_RATES_FILE"`
+	CurrencyRefreshInterval time.Duration `mapstructure:"CURRENCY_REFRESH_INTERVAL"`
+	PaymentRail             string        `mapstructure:"PAYMENT_RAIL"`
+	AllowFakeRail           bool          `mapstructure:"ALLOW_FAKE_RAIL"`
+	FakeRailLimit           int64         `mapstructure:"FAKE_RAIL_LIMIT"`
+	PaymentSettleInterval   time.Duration `mapstructure:"PAYMENT_SETTLE_INTERVAL"`
+	PendingPaymentMinAge    time.Duration `mapstructure:"PENDING_PAYMENT_MIN_AGE"`
+	PendingPaymentTimeout   time.Duration `mapstructure:"PENDING_PAYMENT_TIMEOUT"`
+	SchedulerInterval       time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	SchedulerBatchSize      int32         `mapstructure:"SCHEDULER_BATCH_SIZE"`
+	HoldReleaseInterval     time.Duration `mapstructure:"HOLD_RELEASE_INTERVAL"`
+	TxMaxRetries            int           `mapstructure:"TX_MAX_RETRIES"`
+	TxRetryBaseDelay        time.Duration `mapstructure:"TX_RETRY_BASE_DELAY"`
+	TxRetryMaxDelay         time.Duration `mapstructure:"TX_RETRY_MAX_DELAY"`
+	TxIsolation             string        `mapstructure:"TX_ISOLATION"`
 }
 
 func LoadConfig(path string, devMode bool) (config Config, err error) {
@@ -30,6 +63,13 @@ func LoadConfig(path string, devMode bool) (config Config, err error) {
 
 	viper.AutomaticEnv()
 
+	// ALLOW_FAKE_RAIL is kept out of the env files, which are copied into
+	// the image, so it is only read from the environment.
+	err = viper.BindEnv("ALLOW_FAKE_RAIL")
+	if err != nil {
+		return
+	}
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return
@@ -0,0 +1,18 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigAllowFakeRail(t *testing.T) {
+	config, err := LoadConfig("..", true)
+	require.NoError(t, err)
+	require.False(t, config.AllowFakeRail)
+
+	t.Setenv("ALLOW_FAKE_RAIL", "true")
+	config, err = LoadConfig("..", true)
+	require.NoError(t, err)
+	require.True(t, config.AllowFakeRail)
+}
@@ -1,15 +1,37 @@
 package util
 
+import (
+	"strconv"
+	"strings"
+)
+
+// Currencies seeded in the currencies table, the registry loaded from it is
+// the source of truth for which ones are supported.
 const (
 	USD = "USD"
 	EUR = "EUR"
 	CAD = "CAD"
 )
 
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, CAD:
-		return true
+// FormatAmount writes an amount stored in minor units as a decimal with
+// exponent digits after the separator, e.g. 1234 with exponent 2 is "12.34".
+func FormatAmount(amount int64, exponent int) string {
+	if exponent <= 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+
+	sign := ""
+	units := uint64(amount)
+	if amount < 0 {
+		sign = "-"
+		units = -units
 	}
-	return false
+
+	digits := strconv.FormatUint(units, 10)
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	point := len(digits) - exponent
+	return sign + digits[:point] + "." + digits[point:]
 }
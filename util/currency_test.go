@@ -0,0 +1,28 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAmount(t *testing.T) {
+	testCases := []struct {
+		amount   int64
+		exponent int
+		expected string
+	}{
+		{amount: 1234, exponent: 2, expected: "12.34"},
+		{amount: -1234, exponent: 2, expected: "-12.34"},
+		{amount: 5, exponent: 2, expected: "0.05"},
+		{amount: -5, exponent: 3, expected: "-0.005"},
+		{amount: 0, exponent: 2, expected: "0.00"},
+		{amount: 1234, exponent: 0, expected: "1234"},
+		{amount: math.MinInt64, exponent: 2, expected: "-92233720368547758.08"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, FormatAmount(tc.amount, tc.exponent))
+	}
+}
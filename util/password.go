@@ -1,6 +1,9 @@
 package util
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,3 +21,15 @@ func HashPassword(password string) (string, error) {
 func CheckPassword(password string, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
+
+// HashToken returns the hex SHA-256 of a token. Unlike passwords, tokens are
+// long and random, a fast hash is enough to keep them out of the database.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckToken reports whether the token has the hash, in constant time.
+func CheckToken(token string, hashedToken string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(hashedToken)) == 1
+}
@@ -26,3 +26,16 @@ func TestPassword(t *testing.T) {
 	require.NotEmpty(t, hashedPassword2)
 	require.NotEqual(t, hashedPassword1, hashedPassword2)
 }
+
+func TestToken(t *testing.T) {
+	token := RandomString(32)
+
+	hashedToken := HashToken(token)
+	require.Len(t, hashedToken, 64)
+	require.NotContains(t, hashedToken, token)
+	require.Equal(t, hashedToken, HashToken(token))
+
+	require.True(t, CheckToken(token, hashedToken))
+	require.False(t, CheckToken(RandomString(32), hashedToken))
+	require.False(t, CheckToken(hashedToken, hashedToken))
+}
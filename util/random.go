@@ -29,8 +29,9 @@ func RandomString(n int) string {
 	return sb.String()
 }
 
-func RandomCurrency() string {
-	currencies := []string{EUR, USD, CAD}
+// RandomCurrency picks one of currencies, the codes found in the currency
+// registry, so that tests only use currencies the server supports.
+func RandomCurrency(currencies []string) string {
 	n := len(currencies)
 
 	return currencies[rand.Intn(n)]
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// NextScheduledTime returns the first time strictly after after matched by
+// spec, which is either a standard five field cron expression or a
+// descriptor such as @daily or @every 24h. Times are computed in UTC.
+func NextScheduledTime(spec string, after time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+
+	next := schedule.Next(after.UTC())
+	if next.IsZero() {
+		return next, fmt.Errorf("schedule %q never runs", spec)
+	}
+
+	return next, nil
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextScheduledTime(t *testing.T) {
+	after := time.Date(2024, 3, 18, 10, 30, 0, 0, time.UTC)
+
+	next, err := NextScheduledTime("0 9 * * *", after)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 19, 9, 0, 0, 0, time.UTC), next)
+
+	next, err = NextScheduledTime("@every 1h", after)
+	require.NoError(t, err)
+	require.Equal(t, after.Add(time.Hour), next)
+
+	next, err = NextScheduledTime("@monthly", after)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), next)
+
+	_, err = NextScheduledTime("every day", after)
+	require.Error(t, err)
+
+	_, err = NextScheduledTime("0 0 30 2 *", after)
+	require.Error(t, err)
+}
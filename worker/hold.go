@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"time"
+)
+
+// HoldReleaser gives back the funds reserved by the holds that expired before
+// being captured or voided.
+type HoldReleaser struct {
+	store     db.Store
+	batchSize int32
+	now       func() time.Time
+}
+
+func NewHoldReleaser(store db.Store, batchSize int32) *HoldReleaser {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &HoldReleaser{
+		store:     store,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run releases the expired holds every interval until ctx is done.
+func (releaser *HoldReleaser) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, "expired holds", interval, releaser.ReleaseExpired)
+}
+
+// ReleaseExpired releases the holds expired by now, batch after batch until
+// none is left.
+func (releaser *HoldReleaser) ReleaseExpired(ctx context.Context) error {
+	for {
+		released, err := releaser.store.ReleaseExpiredHolds(ctx, db.ReleaseExpiredHoldsParams{
+			Now:       releaser.now(),
+			BatchSize: releaser.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		if int32(len(released)) < releaser.batchSize {
+			return nil
+		}
+	}
+}
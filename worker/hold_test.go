@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseExpired(t *testing.T) {
+	now := time.Now()
+	hold := db.Hold{ID: 1, AccountID: 10, ToAccountID: 11, Amount: 100, Status: db.HoldExpired}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "Ok",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReleaseExpiredHolds(gomock.Any(), gomock.Eq(db.ReleaseExpiredHoldsParams{
+					Now:       now,
+					BatchSize: 2,
+				})).Times(1).Return([]db.Hold{hold}, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "FullBatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().ReleaseExpiredHolds(gomock.Any(), gomock.Any()).Times(1).Return([]db.Hold{hold, hold}, nil),
+					store.EXPECT().ReleaseExpiredHolds(gomock.Any(), gomock.Any()).Times(1).Return([]db.Hold{}, nil),
+				)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReleaseExpiredHolds(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			releaser := NewHoldReleaser(store, 2)
+			releaser.now = func() time.Time { return now }
+
+			err := releaser.ReleaseExpired(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/payment"
+	"log/slog"
+	"time"
+)
+
+// PaymentSettler settles the payments left pending, because the rail didn't
+// answer when they were made or reported them as pending. They are sent to
+// the rail again, which deduplicates them by payment id, and settled with its
+// answer. Past timeout they are no longer sent, the rail is only asked for
+// their status: a withdrawal is only refunded once the rail reports it
+// failed, since it may have paid it out already.
+type PaymentSettler struct {
+	store     db.Store
+	rail      payment.PaymentRail
+	batchSize int32
+	// minAge leaves the payments just made to the request making them.
+	minAge  time.Duration
+	timeout time.Duration
+	now     func() time.Time
+}
+
+func NewPaymentSettler(store db.Store, rail payment.PaymentRail, batchSize int32, minAge, timeout time.Duration) *PaymentSettler {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &PaymentSettler{
+		store:     store,
+		rail:      rail,
+		batchSize: batchSize,
+		minAge:    minAge,
+		timeout:   timeout,
+		now:       time.Now,
+	}
+}
+
+// Run settles the pending payments every interval until ctx is done.
+func (settler *PaymentSettler) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, "pending payments", interval, settler.SettlePending)
+}
+
+// SettlePending goes through the pending payments batch after batch. A
+// payment that can't be settled is logged and tried again on the next run,
+// it doesn't stop the others.
+func (settler *PaymentSettler) SettlePending(ctx context.Context) error {
+	now := settler.now()
+	var afterID int64
+
+	for {
+		pending, err := settler.store.ListPendingPayments(ctx, db.ListPendingPaymentsParams{
+			CreatedBefore: now.Add(-settler.minAge),
+			AfterID:       afterID,
+			BatchSize:     settler.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, p := range pending {
+			if err := settler.settle(ctx, p, now); err != nil {
+				slog.ErrorContext(ctx, "cannot settle pending payment", "payment_id", p.ID, "error", err)
+			}
+			afterID = p.ID
+		}
+
+		if int32(len(pending)) < settler.batchSize {
+			return nil
+		}
+	}
+}
+
+// settle asks the rail for the outcome of p, sending it again until it times
+// out.
+func (settler *PaymentSettler) settle(ctx context.Context, p db.Payment, now time.Time) error {
+	req := payment.Request{
+		PaymentID: p.ID,
+		AccountID: p.AccountID,
+		Amount:    p.Amount,
+		Currency:  p.Currency,
+	}
+
+	timedOut := now.Sub(p.CreatedAt) >= settler.timeout
+
+	var result payment.Result
+	var err error
+	switch {
+	case timedOut:
+		result, err = settler.rail.Status(ctx, req)
+	case p.Kind == db.PaymentDeposit:
+		result, err = settler.rail.Collect(ctx, req)
+	default:
+		result, err = settler.rail.Payout(ctx, req)
+	}
+	if err != nil {
+		return fmt.Errorf("payment rail error: %w", err)
+	}
+
+	if timedOut && result.Status == payment.StatusPending {
+		return fmt.Errorf("still pending on the payment rail after %s", settler.timeout)
+	}
+
+	arg := db.SettlePaymentParams{
+		ID:            p.ID,
+		Status:        result.Status,
+		RailReference: p.RailReference,
+		FailureReason: sql.NullString{String: result.FailureReason, Valid: result.FailureReason != ""},
+	}
+	if result.Reference != "" {
+		arg.RailReference = sql.NullString{String: result.Reference, Valid: true}
+	}
+
+	_, err = settler.store.SettlePayment(ctx, arg)
+	if errors.Is(err, db.ErrPaymentNotPending) {
+		// settled in the meantime by the request that made it
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,211 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"github/leoflalv/bank-api/payment"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRail answers with the result or error set for each payment, and with
+// statuses to the status lookups.
+type stubRail struct {
+	results     map[int64]payment.Result
+	errs        map[int64]error
+	statuses    map[int64]payment.Result
+	calls       []int64
+	statusCalls []int64
+}
+
+func (rail *stubRail) Collect(ctx context.Context, req payment.Request) (payment.Result, error) {
+	return rail.answer(req)
+}
+
+func (rail *stubRail) Payout(ctx context.Context, req payment.Request) (payment.Result, error) {
+	return rail.answer(req)
+}
+
+func (rail *stubRail) Status(ctx context.Context, req payment.Request) (payment.Result, error) {
+	rail.statusCalls = append(rail.statusCalls, req.PaymentID)
+	return rail.statuses[req.PaymentID], nil
+}
+
+func (rail *stubRail) answer(req payment.Request) (payment.Result, error) {
+	rail.calls = append(rail.calls, req.PaymentID)
+	return rail.results[req.PaymentID], rail.errs[req.PaymentID]
+}
+
+func pendingPayment(id int64, kind string, createdAt time.Time) db.Payment {
+	return db.Payment{
+		ID:        id,
+		AccountID: id * 10,
+		Kind:      kind,
+		Amount:    100,
+		Currency:  "USD",
+		Status:    db.PaymentPending,
+		CreatedBy: "owner",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func TestSettlePending(t *testing.T) {
+	now := time.Now()
+	minAge := time.Minute
+	timeout := time.Hour
+
+	deposit := pendingPayment(1, db.PaymentDeposit, now.Add(-10*time.Minute))
+	unanswered := pendingPayment(2, db.PaymentWithdrawal, now.Add(-10*time.Minute))
+	withdrawal := pendingPayment(3, db.PaymentWithdrawal, now.Add(-10*time.Minute))
+	timedOut := pendingPayment(4, db.PaymentDeposit, now.Add(-2*time.Hour))
+	paidOut := pendingPayment(5, db.PaymentWithdrawal, now.Add(-2*time.Hour))
+	stillPending := pendingPayment(6, db.PaymentWithdrawal, now.Add(-2*time.Hour))
+
+	testCases := []struct {
+		name       string
+		batchSize  int32
+		rail       *stubRail
+		buildStubs func(store *mockdb.MockStore)
+		checkRail  func(t *testing.T, rail *stubRail)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:      "SettledAndTimedOut",
+			batchSize: 10,
+			rail: &stubRail{
+				results: map[int64]payment.Result{
+					deposit.ID:    {Reference: "rail-1", Status: payment.StatusCompleted},
+					withdrawal.ID: {Reference: "rail-3", Status: payment.StatusFailed, FailureReason: "closed account"},
+				},
+				errs: map[int64]error{unanswered.ID: errors.New("rail unavailable")},
+				statuses: map[int64]payment.Result{
+					timedOut.ID:     {Reference: "rail-4", Status: payment.StatusFailed, FailureReason: "expired"},
+					paidOut.ID:      {Reference: "rail-5", Status: payment.StatusCompleted},
+					stillPending.ID: {Reference: "rail-6", Status: payment.StatusPending},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPendingPayments(gomock.Any(), gomock.Eq(db.ListPendingPaymentsParams{
+					CreatedBefore: now.Add(-minAge),
+					BatchSize:     10,
+				})).Times(1).Return([]db.Payment{deposit, unanswered, withdrawal, timedOut, paidOut, stillPending}, nil)
+
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Eq(db.SettlePaymentParams{
+					ID:            deposit.ID,
+					Status:        db.PaymentCompleted,
+					RailReference: sql.NullString{String: "rail-1", Valid: true},
+				})).Times(1)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Eq(db.SettlePaymentParams{
+					ID:            withdrawal.ID,
+					Status:        db.PaymentFailed,
+					RailReference: sql.NullString{String: "rail-3", Valid: true},
+					FailureReason: sql.NullString{String: "closed account", Valid: true},
+				})).Times(1)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Eq(db.SettlePaymentParams{
+					ID:            timedOut.ID,
+					Status:        db.PaymentFailed,
+					RailReference: sql.NullString{String: "rail-4", Valid: true},
+					FailureReason: sql.NullString{String: "expired", Valid: true},
+				})).Times(1)
+				// completed by the rail after the timeout, it isn't refunded
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Eq(db.SettlePaymentParams{
+					ID:            paidOut.ID,
+					Status:        db.PaymentCompleted,
+					RailReference: sql.NullString{String: "rail-5", Valid: true},
+				})).Times(1)
+			},
+			checkRail: func(t *testing.T, rail *stubRail) {
+				// the payments timed out aren't sent again, only looked up, and
+				// the one still pending on the rail isn't settled
+				require.Equal(t, []int64{deposit.ID, unanswered.ID, withdrawal.ID}, rail.calls)
+				require.Equal(t, []int64{timedOut.ID, paidOut.ID, stillPending.ID}, rail.statusCalls)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:      "FullBatch",
+			batchSize: 1,
+			rail: &stubRail{
+				results: map[int64]payment.Result{deposit.ID: {Status: payment.StatusPending}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().ListPendingPayments(gomock.Any(), gomock.Eq(db.ListPendingPaymentsParams{
+						CreatedBefore: now.Add(-minAge),
+						BatchSize:     1,
+					})).Times(1).Return([]db.Payment{deposit}, nil),
+					store.EXPECT().ListPendingPayments(gomock.Any(), gomock.Eq(db.ListPendingPaymentsParams{
+						CreatedBefore: now.Add(-minAge),
+						AfterID:       deposit.ID,
+						BatchSize:     1,
+					})).Times(1).Return(nil, nil),
+				)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkRail: func(t *testing.T, rail *stubRail) {
+				require.Equal(t, []int64{deposit.ID}, rail.calls)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:      "AlreadySettled",
+			batchSize: 10,
+			rail: &stubRail{
+				results: map[int64]payment.Result{deposit.ID: {Status: payment.StatusCompleted}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPendingPayments(gomock.Any(), gomock.Any()).Times(1).Return([]db.Payment{deposit}, nil)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Any()).Times(1).Return(db.Payment{}, db.ErrPaymentNotPending)
+			},
+			checkRail: func(t *testing.T, rail *stubRail) {},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:      "ListError",
+			batchSize: 10,
+			rail:      &stubRail{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPendingPayments(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().SettlePayment(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkRail: func(t *testing.T, rail *stubRail) {
+				require.Empty(t, rail.calls)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			settler := NewPaymentSettler(store, tc.rail, tc.batchSize, minAge, timeout)
+			settler.now = func() time.Time { return now }
+
+			err := settler.SettlePending(context.Background())
+			tc.checkError(t, err)
+			tc.checkRail(t, tc.rail)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"time"
+)
+
+// defaultBatchSize is used when the configuration doesn't set one.
+const defaultBatchSize = 100
+
+// ScheduledTransferRunner executes the scheduled transfers as they fall due.
+// Several runners can share the same database, a due transfer is claimed by
+// only one of them.
+type ScheduledTransferRunner struct {
+	store     db.Store
+	batchSize int32
+	now       func() time.Time
+}
+
+func NewScheduledTransferRunner(store db.Store, batchSize int32) *ScheduledTransferRunner {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &ScheduledTransferRunner{
+		store:     store,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run executes the due transfers every interval until ctx is done.
+func (runner *ScheduledTransferRunner) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, "scheduled transfers", interval, runner.RunDue)
+}
+
+// RunDue executes the transfers due now, batch after batch until none is left.
+// A failed transfer is recorded in the history of its scheduled transfer and
+// doesn't stop the others.
+func (runner *ScheduledTransferRunner) RunDue(ctx context.Context) error {
+	for {
+		runs, err := runner.store.RunScheduledTransfers(ctx, db.RunScheduledTransfersParams{
+			Now:       runner.now(),
+			BatchSize: runner.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		if int32(len(runs)) < runner.batchSize {
+			return nil
+		}
+	}
+}
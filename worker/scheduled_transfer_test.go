@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github/leoflalv/bank-api/db/mock"
+	db "github/leoflalv/bank-api/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDue(t *testing.T) {
+	now := time.Now()
+	succeeded := db.ScheduledTransferRun{
+		ID:                  1,
+		ScheduledTransferID: 1,
+		ScheduledFor:        now.Add(-time.Minute),
+		Status:              db.ScheduledRunSucceeded,
+		TransferID:          sql.NullInt64{Int64: 7, Valid: true},
+	}
+	failed := db.ScheduledTransferRun{
+		ID:                  2,
+		ScheduledTransferID: 2,
+		ScheduledFor:        now.Add(-time.Hour),
+		Status:              db.ScheduledRunFailed,
+		Error:               sql.NullString{String: db.ErrInsufficientFunds.Error(), Valid: true},
+	}
+
+	testCases := []struct {
+		name       string
+		batchSize  int32
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:      "SucceededAndFailed",
+			batchSize: 10,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RunScheduledTransfers(gomock.Any(), gomock.Eq(db.RunScheduledTransfersParams{
+					Now:       now,
+					BatchSize: 10,
+				})).Times(1).Return([]db.ScheduledTransferRun{succeeded, failed}, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:      "FullBatch",
+			batchSize: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().RunScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.ScheduledTransferRun{succeeded, failed}, nil),
+					store.EXPECT().RunScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil),
+				)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:      "RunError",
+			batchSize: 10,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RunScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			runner := NewScheduledTransferRunner(store, tc.batchSize)
+			runner.now = func() time.Time { return now }
+
+			err := runner.RunDue(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Package worker holds the jobs running in the background of the API server.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// runPeriodically calls job every interval until ctx is done. There is nobody
+// to return the errors to, so they are logged and the job tried again on the
+// next tick.
+func runPeriodically(ctx context.Context, name string, interval time.Duration, job func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx); err != nil {
+			slog.ErrorContext(ctx, "background job failed", "job", name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}